@@ -0,0 +1,47 @@
+package main
+
+import (
+	// std
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Collects every --input flag given; each may be a path or a glob pattern
+type inputFlags []string
+
+func (f *inputFlags) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *inputFlags) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// Expands any glob patterns into the paths they match, in the order the flags were
+// given, dropping paths named more than once.
+func (f inputFlags) expand() ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, pattern := range f {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, fmt.Errorf("invalid input pattern %s: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no inputs match %s", pattern)
+			}
+		}
+
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				paths = append(paths, match)
+			}
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	// std
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/datarequest"
+	"github.com/hoodnoah/cod_data_request/internal/input"
+)
+
+// Lists every section and table in an export, and whether it is supported
+func runInspect(args []string) {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	inputPath := fs.String("input", "", "Path to the HTML file or .zip archive, optionally gzip or zstd compressed, or - for stdin (required)")
+	asJSON := fs.Bool("json", false, "Print the sections as JSON instead of a table")
+	headings := fs.String("headings", "", "JSON file of extra H1/H2 titles and patterns per table, for renamed sections (optional)")
+	tableSpecs := fs.String("tables", "", "YAML or JSON file of table specs, for sections with no built-in table (optional)")
+	fs.Parse(args)
+
+	if *inputPath == "" {
+		log.Fatal("You must specify --input HTML file or .zip archive path")
+	}
+	if *tableSpecs != "" {
+		loadTableSpecs(*tableSpecs)
+	}
+	if *headings != "" {
+		loadHeadings(*headings)
+	}
+
+	in, err := input.Open(*inputPath)
+	if err != nil {
+		log.Fatalf("Failed to open input %s: %v", *inputPath, err)
+	}
+	defer in.Close()
+
+	doc, err := in.Document()
+	if err != nil {
+		log.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	summaries := datarequest.Inspect(doc)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(summaries); err != nil {
+			log.Fatalf("failed to write JSON: %v", err)
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "H1\tH2\tROWS\tCOLUMNS\tTABLE")
+	for _, s := range summaries {
+		table := s.Table
+		if !s.Supported {
+			table = "(unsupported)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", s.H1, s.H2, s.Rows, s.Columns, table)
+	}
+	w.Flush()
+}
@@ -5,53 +5,297 @@ import (
 	"flag"
 	"log"
 	"os"
-
-	// external
-	"github.com/PuerkitoBio/goquery"
+	"strings"
+	"time"
 
 	// internal
 	"github.com/hoodnoah/cod_data_request/internal/datarequest"
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/spec"
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+	"github.com/hoodnoah/cod_data_request/internal/input"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "inspect" {
+		runInspect(os.Args[2:])
+		return
+	}
+
 	// define flags
-	inputPath := flag.String("input", "", "Path to the HTML file (required)")
+	var inputs inputFlags
+	flag.Var(&inputs, "input", "Path or glob of the HTML file or .zip archive, optionally gzip or zstd compressed, or - for stdin (required); repeat to merge several exports")
 	csvDir := flag.String("csv", "", "Directory to write CSV output (optional)")
 	parquetDir := flag.String("parquet", "", "Directory to write parquet output (optional)")
+	strict := flag.Bool("strict", false, "Fail if any table's section is missing, instead of leaving it empty")
+	unrecognized := flag.Bool("generic", false, "Also export tables with no registered handler, inferring their column types")
+	rejectsDir := flag.String("rejects", "", "Directory to write rejected rows (optional, defaults to the CSV or parquet directory)")
+	maxRejects := flag.Int("max-rejects", 0, "Rejected rows allowed per table before failing; negative for no limit")
+	maxRejectPct := flag.Float64("max-reject-pct", 0, "Percentage of rows allowed to be rejected per table before failing; 0 for no limit")
+	strictSchema := flag.Bool("strict-schema", false, "Fail if a table gains or loses columns, instead of ignoring or zeroing them")
+	driftReport := flag.String("drift-report", "", "File to write the JSON schema drift report to (optional)")
+	stream := flag.Bool("stream", false, "Stream the HTML instead of loading it into memory; for very large exports")
+	parallel := flag.Int("parallel", 0, "Tables parsed and written at once; 0 for one per CPU, 1 to run one at a time")
+	appendMode := flag.Bool("append", false, "Only write rows newer than those already in the output directories; parquet rows go in new part files")
+	headings := flag.String("headings", "", "JSON file of extra H1/H2 titles and patterns per table, for renamed sections (optional)")
+	tableSpecs := flag.String("tables", "", "YAML or JSON file of table specs, for sections with no built-in table (optional)")
 	flag.Parse()
 
-	if *inputPath == "" {
-		log.Fatal("You must specify --input HTML file path")
+	if len(inputs) == 0 {
+		log.Fatal("You must specify --input HTML file or .zip archive path")
 	}
-
-	// Open, parse HTML file
-	f, err := os.Open(*inputPath)
+	inputPaths, err := inputs.expand()
 	if err != nil {
-		log.Fatalf("Failed to open file %s: %v", *inputPath, err)
+		log.Fatal(err)
 	}
-	defer f.Close()
 
-	doc, err := goquery.NewDocumentFromReader(f)
-	if err != nil {
-		log.Fatalf("Failed to parse HTML: %v", err)
+	if *appendMode && *unrecognized {
+		log.Fatal("--append cannot be combined with --generic")
+	}
+
+	if *tableSpecs != "" {
+		loadTableSpecs(*tableSpecs)
+	}
+	if *headings != "" {
+		loadHeadings(*headings)
+	}
+
+	opts := datarequest.ParseOptions{
+		Lenient:      !*strict,
+		Unrecognized: *unrecognized,
+		Budget: helpers.ErrorBudget{
+			MaxRows:    *maxRejects,
+			MaxPercent: *maxRejectPct,
+		},
+		Parallelism: *parallel,
+	}
+	if *strictSchema {
+		opts.Drift = helpers.DriftStrict
 	}
 
-	datarequest := datarequest.NewCodDataRequest()
-	if err := datarequest.ParseHtml(doc); err != nil {
-		log.Fatalf("failed to parse cod data request: %v", err)
+	var (
+		request datarequest.CodDataRequest
+		report  *datarequest.ParseReport
+	)
+	if len(inputPaths) == 1 {
+		request, report = parseInput(inputPaths[0], opts, *stream)
+	} else {
+		request, report = mergeInputs(inputPaths, opts, *stream)
+	}
+	printParseReport(report)
+
+	if *driftReport != "" {
+		if err := report.WriteDriftReport(*driftReport); err != nil {
+			log.Fatalf("failed to write drift report: %v", err)
+		}
+	}
+
+	if dir := firstNonEmpty(*rejectsDir, *csvDir, *parquetDir); dir != "" {
+		if err := report.WriteRejects(dir); err != nil {
+			log.Fatalf("failed to write rejected rows: %v", err)
+		}
+	}
+
+	if *appendMode {
+		appendOutput(&request, *csvDir, *parquetDir, *parallel)
+	} else {
+		writeOutput(&request, *csvDir, *parquetDir, *parallel)
+	}
+
+	for _, table := range request.Unrecognized {
+		log.Printf("Extracted unrecognized table %s (%d rows)\n", table.Name, len(table.Rows))
+		for i, column := range table.Columns {
+			log.Printf("  %s: %s\n", column, table.Types[i])
+		}
 	}
+}
 
-	if *csvDir != "" {
-		if err := datarequest.ToCSV(*csvDir); err != nil {
+func writeOutput(request *datarequest.CodDataRequest, csvDir, parquetDir string, parallel int) {
+	if csvDir != "" {
+		if err := request.ToCSV(csvDir, parallel); err != nil {
 			log.Fatalf("failed to write records to CSV: %v", err)
 		}
-		log.Printf("CSV saved to %s\n", *csvDir)
+		log.Printf("CSV saved to %s\n", csvDir)
 	}
 
-	if *parquetDir != "" {
-		if err := datarequest.ToParquet(*parquetDir); err != nil {
+	if parquetDir != "" {
+		if err := request.ToParquet(parquetDir, parallel); err != nil {
 			log.Fatalf("failed to write records to parquet: %v", err)
 		}
-		log.Printf("Parquet saved to %s\n", *parquetDir)
+		log.Printf("Parquet saved to %s\n", parquetDir)
+	}
+}
+
+// Appends only the newer rows to existing output. Both outputs are checked before
+// either is written, so a schema mismatch in one leaves both untouched.
+func appendOutput(request *datarequest.CodDataRequest, csvDir, parquetDir string, parallel int) {
+	var pending []*datarequest.PendingAppend
+	if csvDir != "" {
+		csvAppend, err := request.PlanCSVAppend(csvDir, parallel)
+		if err != nil {
+			log.Fatalf("cannot append records to CSV: %v", err)
+		}
+		pending = append(pending, csvAppend)
+	}
+	if parquetDir != "" {
+		parquetAppend, err := request.PlanParquetAppend(parquetDir, parallel)
+		if err != nil {
+			log.Fatalf("cannot append records to parquet: %v", err)
+		}
+		pending = append(pending, parquetAppend)
+	}
+
+	for _, p := range pending {
+		appendReport, err := p.Write()
+		if err != nil {
+			log.Fatalf("failed to append records: %v", err)
+		}
+		printAppendReport(appendReport)
+	}
+}
+
+// Opens and parses a single export, exiting on failure
+func parseInput(inputPath string, opts datarequest.ParseOptions, stream bool) (datarequest.CodDataRequest, *datarequest.ParseReport) {
+	// Open input, parse HTML part(s)
+	in, err := input.Open(inputPath)
+	if err != nil {
+		log.Fatalf("Failed to open input %s: %v", inputPath, err)
+	}
+	defer in.Close()
+
+	request := datarequest.NewCodDataRequest()
+
+	var report *datarequest.ParseReport
+	if stream {
+		r := in.Reader()
+		defer r.Close()
+
+		report, err = request.ParseReader(r, opts)
+	} else {
+		doc, docErr := in.Document()
+		if docErr != nil {
+			log.Fatalf("Failed to parse HTML: %v", docErr)
+		}
+
+		report, err = request.ParseHtml(doc, opts)
+	}
+	// after parsing, to report any switch of encoding made while reading
+	printInputReport(in.Report)
+	if err != nil {
+		log.Fatalf("failed to parse cod data request %s: %v", inputPath, err)
+	}
+	return request, report
+}
+
+// Parses each export in turn, merging it into those before it and dropping rows
+// already seen
+func mergeInputs(inputPaths []string, opts datarequest.ParseOptions, stream bool) (datarequest.CodDataRequest, *datarequest.ParseReport) {
+	request := datarequest.NewCodDataRequest()
+	report := &datarequest.ParseReport{}
+	for _, inputPath := range inputPaths {
+		next, nextReport := parseInput(inputPath, opts, stream)
+		report.Append(inputPath, nextReport)
+		printMergeReport(inputPath, request.Merge(&next))
+	}
+	return request, report
+}
+
+func loadHeadings(fileName string) {
+	overrides, err := helpers.LoadLocatorOverrides(fileName)
+	if err != nil {
+		log.Fatalf("Failed to read heading overrides: %v", err)
+	}
+	if err := datarequest.ExtendHeadings(overrides); err != nil {
+		log.Fatalf("Failed to apply heading overrides: %v", err)
+	}
+}
+
+func loadTableSpecs(fileName string) {
+	if err := spec.Register(fileName); err != nil {
+		log.Fatalf("Failed to load table specs: %v", err)
+	}
+}
+
+func printInputReport(report input.Report) {
+	if report.Compression != "" {
+		log.Printf("Decompressed %s (%s)\n", report.Source, report.Compression)
+	}
+	if len(report.Encodings) > 0 {
+		log.Printf("Read %s as %s\n", report.Source, strings.Join(report.Encodings, ", "))
+	}
+	if !report.Archive {
+		return
+	}
+
+	log.Printf("Read archive %s\n", report.Source)
+	for _, name := range report.Used {
+		log.Printf("  used:    %s\n", name)
+	}
+	for _, skipped := range report.Skipped {
+		log.Printf("  skipped: %s (%s)\n", skipped.Name, skipped.Reason)
+	}
+}
+
+func printMergeReport(source string, report datarequest.MergeReport) {
+	added, overlapping := report.Totals()
+	log.Printf("Merged %s: %d new rows, %d overlapping\n", source, added, overlapping)
+	for _, status := range report.Tables {
+		if status.New > 0 || status.Overlapping > 0 {
+			log.Printf("  %s: %d new, %d overlapping\n", status.Table, status.New, status.Overlapping)
+		}
+	}
+}
+
+func printAppendReport(report datarequest.AppendReport) {
+	for _, status := range report.Tables {
+		if !status.Existing {
+			log.Printf("%s: %d rows written to new %s\n", status.Table, status.Written, status.File)
+			continue
+		}
+		since := time.UnixMilli(status.Since).UTC().Format(time.RFC3339)
+		if status.Written == 0 {
+			log.Printf("%s: nothing newer than %s, %d rows skipped\n", status.Table, since, status.Skipped)
+			continue
+		}
+		log.Printf("%s: %d rows newer than %s written to %s, %d skipped\n", status.Table, status.Written, since, status.File, status.Skipped)
+	}
+}
+
+func printParseReport(report *datarequest.ParseReport) {
+	for _, status := range report.Tables {
+		if status.Source != "" {
+			status.Table = status.Source + " " + status.Table
+		}
+		if status.Missing {
+			log.Printf("%s: missing, left empty\n", status.Table)
+		} else {
+			log.Printf("%s: %d rows\n", status.Table, status.Rows)
+		}
+		if len(status.Drift.Unknown) > 0 {
+			log.Printf("%s: ignored unknown columns %q\n", status.Table, status.Drift.Unknown)
+		}
+		if len(status.Drift.Missing) > 0 {
+			log.Printf("%s: missing columns %q, left empty\n", status.Table, status.Drift.Missing)
+		}
+		if len(status.Drift.Duplicate) > 0 {
+			log.Printf("%s: duplicate columns %q, the last parsed\n", status.Table, status.Drift.Duplicate)
+		}
+		if status.Drift.Reordered {
+			log.Printf("%s: columns reordered\n", status.Table)
+		}
+		if len(status.Rejects) > 0 {
+			log.Printf("%s: %d rows rejected\n", status.Table, len(status.Rejects))
+		}
+	}
+	for _, warning := range report.Warnings() {
+		log.Printf("warning: %s\n", warning)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
 	}
+	return ""
 }
@@ -0,0 +1,484 @@
+// Scaffolds a table package from a sample export: it reads the table under the
+// given H1 and H2, infers each column's type from its cells, and writes
+// <dir>/<name>/<name>.go in the style of the existing table packages, along with a
+// fixture test over a few of the sample's rows. The package is then wired into the
+// table set by a blank import in <dir>/tables.go.
+//
+// Run it from the repository root:
+//
+//	go run ./cmd/scaffold -input export.html -h1 "Call of Duty: Black Ops 6" \
+//		-h2 "Zombies Match Data (reverse chronological)" -name blops6zombies
+//
+// or from a go:generate directive in internal/datarequest, passing -dir .:
+//
+//	//go:generate go run ../../cmd/scaffold -dir . -input export.html -h1 ... -h2 ... -name ...
+//
+// The generated package is a starting point: review the inferred types, the
+// natural key and the output names before committing it.
+package main
+
+import (
+	// std
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+	"github.com/hoodnoah/cod_data_request/internal/input"
+)
+
+// The number of sample rows copied into the fixture
+const fixtureRows = 5
+
+var packageName = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+func main() {
+	inputPath := flag.String("input", "", "Path to the HTML file or .zip archive, optionally gzip or zstd compressed (required)")
+	h1 := flag.String("h1", "", "H1 heading of the table's section (required)")
+	h2 := flag.String("h2", "", "H2 heading of the table's section (required)")
+	name := flag.String("name", "", "Package and table name, lower case letters and digits, e.g. blops6zombies (required)")
+	typeName := flag.String("type", "", "Record type name; defaults to the H2 title, e.g. ZombiesMatch for \"Zombies Match Data\"")
+	outputName := flag.String("output", "", "Output file name without extension; defaults to the H1 title and plural record type, e.g. black_ops_6_zombies_matches")
+	dir := flag.String("dir", "internal/datarequest", "Directory holding the table packages and tables.go")
+	force := flag.Bool("force", false, "Overwrite an existing package")
+	flag.Parse()
+
+	if *inputPath == "" || *h1 == "" || *h2 == "" || *name == "" {
+		log.Fatal("You must specify -input, -h1, -h2 and -name")
+	}
+	if !packageName.MatchString(*name) {
+		log.Fatalf("-name %q must be lower case letters and digits, starting with a letter", *name)
+	}
+
+	pkg, err := loadPackage(*inputPath, *h1, *h2, *name, *typeName, *outputName, *dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pkgDir := filepath.Join(*dir, *name)
+	if _, err := os.Stat(pkgDir); err == nil && !*force {
+		log.Fatalf("%s already exists; pass -force to overwrite it", pkgDir)
+	}
+	if err := pkg.write(pkgDir); err != nil {
+		log.Fatal(err)
+	}
+	if err := wireTable(filepath.Join(*dir, "tables.go"), pkg.Module+"/internal/datarequest/"+*name); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Wrote %s with %d columns from %d sample rows\n", pkgDir, len(pkg.Columns), len(pkg.rows))
+}
+
+// Infers the package to generate from the sample table under the headings, naming
+// its record type and output files by default from the headings
+func loadPackage(inputPath, h1, h2, name, typeName, outputName, dir string) (*tablePackage, error) {
+	header, rows, err := readTable(inputPath, h1, h2)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := newPackage(name, h1, h2, header, rows)
+	if err != nil {
+		return nil, err
+	}
+	if typeName != "" {
+		pkg.Type = typeName
+	}
+	pkg.Types = plural(pkg.Type)
+	pkg.OutputName = outputName
+	if pkg.OutputName == "" {
+		pkg.OutputName = helpers.ToSnakeCase(strings.TrimPrefix(h1, "Call of Duty:") + " " + splitWords(pkg.Types))
+	}
+
+	pkg.Module, err = modulePath(dir)
+	if err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// Reads the header and rows of the first section under the headings
+func readTable(inputPath, h1, h2 string) ([]string, [][]string, error) {
+	in, err := input.Open(inputPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open input %s: %w", inputPath, err)
+	}
+	defer in.Close()
+
+	doc, err := in.Document()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	header, rows, err := helpers.FindTable(doc, helpers.NewLocator(h1, h2))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("the table under H1 %s, H2 %s has no rows to infer types from", h1, h2)
+	}
+	return header, rows, nil
+}
+
+// A column of the generated record type
+type column struct {
+	Label   string // the export's header cell
+	Field   string // the Go field name
+	Parquet string // the parquet and CSV column name
+	Type    helpers.ColumnType
+
+	// a string column whose cells all read as lengths of time, e.g. "1:30:00"
+	Duration bool
+}
+
+// The Go type, parquet tag and parser of each inferred column type. Numbers are
+// nullable, as in the existing packages; strings keep their cell's text.
+func (c column) GoType() string {
+	switch {
+	case c.Label == helpers.TimestampColumn:
+		return "int64"
+	case c.Duration:
+		return "*time.Duration"
+	case c.Type == helpers.ColumnInt:
+		return "*int64"
+	case c.Type == helpers.ColumnFloat, c.Type == helpers.ColumnPercent:
+		return "*float64"
+	case c.Type == helpers.ColumnTimestamp:
+		return "*int64"
+	default:
+		return "string"
+	}
+}
+
+func (c column) ParquetTag() string {
+	switch {
+	case c.Label == helpers.TimestampColumn:
+		return fmt.Sprintf("name=%s, type=INT64, convertedtype=TIMESTAMP_MILLIS", c.Parquet)
+	case c.Duration:
+		return fmt.Sprintf("name=%s_ns, type=INT64, repetitiontype=OPTIONAL", c.Parquet)
+	case c.Type == helpers.ColumnInt:
+		return fmt.Sprintf("name=%s, type=INT64, repetitiontype=OPTIONAL", c.Parquet)
+	case c.Type == helpers.ColumnFloat, c.Type == helpers.ColumnPercent:
+		return fmt.Sprintf("name=%s, type=DOUBLE, repetitiontype=OPTIONAL", c.Parquet)
+	case c.Type == helpers.ColumnTimestamp:
+		return fmt.Sprintf("name=%s, type=INT64, convertedtype=TIMESTAMP_MILLIS, repetitiontype=OPTIONAL", c.Parquet)
+	default:
+		return fmt.Sprintf("name=%s, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY", c.Parquet)
+	}
+}
+
+func (c column) Parser() string {
+	switch {
+	case c.Label == helpers.TimestampColumn, c.Type == helpers.ColumnTimestamp:
+		return "helpers.TimestampToUnixMillisInt64()"
+	case c.Duration:
+		return "helpers.DurationParser()"
+	case c.Type == helpers.ColumnInt:
+		return "helpers.IntParser()"
+	case c.Type == helpers.ColumnFloat, c.Type == helpers.ColumnPercent:
+		return "helpers.FloatParser()"
+	default:
+		return "helpers.StringParser()"
+	}
+}
+
+// Returns the Go expression of the field value a cell decodes to, for the
+// fixture test; numbers are built with its ptr function
+func (c column) Literal(cell string) (string, error) {
+	var parse helpers.FieldParser
+	switch {
+	case c.Label == helpers.TimestampColumn, c.Type == helpers.ColumnTimestamp:
+		parse = helpers.TimestampToUnixMillisInt64()
+	case c.Duration:
+		parse = helpers.DurationParser()
+	case c.Type == helpers.ColumnInt:
+		parse = helpers.IntParser()
+	case c.Type == helpers.ColumnFloat, c.Type == helpers.ColumnPercent:
+		parse = helpers.FloatParser()
+	default:
+		return strconv.Quote(cell), nil
+	}
+
+	val, err := parse(cell)
+	if err != nil {
+		return "", fmt.Errorf("column %q: %w", c.Label, err)
+	}
+	switch val := val.(type) {
+	case nil:
+		if c.Label == helpers.TimestampColumn {
+			return "", fmt.Errorf("column %q is empty", c.Label)
+		}
+		return "nil", nil
+	case int64:
+		if c.Label == helpers.TimestampColumn {
+			return strconv.FormatInt(val, 10), nil
+		}
+		return fmt.Sprintf("ptr[int64](%d)", val), nil
+	case float64:
+		return fmt.Sprintf("ptr[float64](%s)", strconv.FormatFloat(val, 'g', -1, 64)), nil
+	default:
+		return fmt.Sprintf("ptr[%T](%d)", val, val), nil
+	}
+}
+
+// A field of the record the first fixture row decodes to
+type fieldValue struct {
+	Field string
+	Value string // a Go expression
+}
+
+// Describes the package to generate
+type tablePackage struct {
+	Module     string
+	Name       string
+	H1, H2     string
+	H2Title    string // the H2 without its qualifier
+	Type       string
+	Types      string
+	OutputName string
+	Columns    []column
+	Key        []string     // the fields of the natural key
+	First      []fieldValue // the record of the first fixture row
+
+	header []string
+	rows   [][]string
+}
+
+// Infers the package's columns from the sample's header and rows
+func newPackage(name, h1, h2 string, header []string, rows [][]string) (*tablePackage, error) {
+	if !slices.Contains(header, helpers.TimestampColumn) {
+		return nil, fmt.Errorf("the table has no %q column, which every table is ordered and appended by", helpers.TimestampColumn)
+	}
+
+	pkg := &tablePackage{
+		Name:    name,
+		H1:      h1,
+		H2:      h2,
+		H2Title: strings.TrimSpace(qualifier.ReplaceAllString(h2, "")),
+		header:  header,
+		rows:    rows,
+	}
+	pkg.Type = exportedName(strings.TrimSuffix(pkg.H2Title, " Data"))
+
+	fields := make(map[string]bool)
+	for i, label := range header {
+		if label == "" {
+			return nil, fmt.Errorf("column %d has no header", i+1)
+		}
+
+		cells := make([]string, 0, len(rows))
+		for _, row := range rows {
+			if i < len(row) {
+				cells = append(cells, row[i])
+			}
+		}
+
+		col := column{Label: label, Field: exportedName(label), Parquet: helpers.ToSnakeCase(label), Type: helpers.InferColumnType(cells)}
+		col.Duration = col.Type == helpers.ColumnString && isDuration(cells)
+		if label == helpers.TimestampColumn {
+			col.Field, col.Parquet = "Timestamp", "timestamp_utc"
+		}
+		if col.Field == "" || fields[col.Field] {
+			return nil, fmt.Errorf("column %q does not make a unique field name", label)
+		}
+		fields[col.Field] = true
+		pkg.Columns = append(pkg.Columns, col)
+
+		// the timestamp and the descriptive columns identify a record
+		if col.Label == helpers.TimestampColumn || col.GoType() == "string" {
+			pkg.Key = append(pkg.Key, col.Field)
+		}
+
+		var cell string
+		if i < len(rows[0]) {
+			cell = rows[0][i]
+		}
+		value, err := col.Literal(cell)
+		if err != nil {
+			return nil, fmt.Errorf("the first sample row: %w", err)
+		}
+		pkg.First = append(pkg.First, fieldValue{Field: col.Field, Value: value})
+	}
+	return pkg, nil
+}
+
+// Reports whether every cell is null or reads as a length of time, and some are not null
+func isDuration(cells []string) bool {
+	parse := helpers.DurationParser()
+	found := false
+	for _, cell := range cells {
+		if helpers.IsNull(cell) {
+			continue
+		}
+		if _, err := parse(cell); err != nil {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// Reports whether any column is a duration, which needs the time package
+func (p *tablePackage) HasDuration() bool {
+	return slices.ContainsFunc(p.Columns, func(c column) bool { return c.Duration })
+}
+
+// Matches a trailing parenthetical qualifier, e.g. " (reverse chronological)"
+var qualifier = regexp.MustCompile(`\s*\(.*\)$`)
+
+// Converts a header cell into an exported Go identifier, e.g. "XP at Start" to XPAtStart
+func exportedName(label string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(label, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	name := b.String()
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		name = "Column" + name
+	}
+	return name
+}
+
+// Splits a Go identifier into its words, e.g. ZombiesMatches to "Zombies Matches"
+func splitWords(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Returns the plural of an English type name, e.g. Match to Matches
+func plural(name string) string {
+	for _, suffix := range []string{"s", "x", "ch", "sh"} {
+		if strings.HasSuffix(name, suffix) {
+			return name + "es"
+		}
+	}
+	return name + "s"
+}
+
+// Writes the package source, its fixture test and the fixture itself
+func (p *tablePackage) write(pkgDir string) error {
+	if err := os.MkdirAll(filepath.Join(pkgDir, "testdata"), 0o755); err != nil {
+		return err
+	}
+
+	for fileName, tmpl := range map[string]string{
+		p.Name + ".go":      packageTemplate,
+		p.Name + "_test.go": testTemplate,
+	} {
+		if err := writeGo(filepath.Join(pkgDir, fileName), tmpl, p); err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(filepath.Join(pkgDir, "testdata", p.Name+".html"), p.fixture(), 0o644)
+}
+
+// Returns an export holding only the table's section and its first sample rows
+func (p *tablePackage) fixture() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<html><body>\n<h1>%s</h1>\n<h2>%s</h2>\n<table>\n<tr>", escape(p.H1), escape(p.H2))
+	for _, label := range p.header {
+		fmt.Fprintf(&b, "<th>%s</th>", escape(label))
+	}
+	b.WriteString("</tr>\n")
+	for _, row := range p.rows[:min(fixtureRows, len(p.rows))] {
+		b.WriteString("<tr>")
+		for _, cell := range row {
+			fmt.Fprintf(&b, "<td>%s</td>", escape(cell))
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</table>\n</body></html>\n")
+	return b.Bytes()
+}
+
+// Returns the number of rows in the fixture
+func (p *tablePackage) FixtureRows() int {
+	return min(fixtureRows, len(p.rows))
+}
+
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// Reads the module path from the go.mod in or above dir
+func modulePath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		data, err := os.ReadFile(filepath.Join(abs, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if path, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+					return strings.Trim(strings.TrimSpace(path), `"`), nil
+				}
+			}
+			return "", fmt.Errorf("%s has no module directive", filepath.Join(abs, "go.mod"))
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", fmt.Errorf("no go.mod found above %s", dir)
+		}
+		abs = parent
+	}
+}
+
+// Adds a blank import of the package to tables.go, keeping the imports sorted
+// by path, as packages register their tables in import path order
+func wireTable(tablesFile, importPath string) error {
+	data, err := os.ReadFile(tablesFile)
+	if err != nil {
+		return err
+	}
+	src := string(data)
+
+	line := fmt.Sprintf("\t_ %q\n", importPath)
+	if strings.Contains(src, line) {
+		return nil
+	}
+
+	// insert before the first blank import which sorts after it, or after the last
+	blank := regexp.MustCompile(`(?m)^\t_ "([^"]+)"\n`)
+	at := -1
+	for _, m := range blank.FindAllStringSubmatchIndex(src, -1) {
+		at = m[1]
+		if src[m[2]:m[3]] > importPath {
+			at = m[0]
+			break
+		}
+	}
+	if at < 0 {
+		return fmt.Errorf("%s has no blank imports to add %s to", tablesFile, importPath)
+	}
+
+	formatted, err := format.Source([]byte(src[:at] + line + src[at:]))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(tablesFile, formatted, 0o644)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	// std
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// Generates packages from the sample export, comparing every file written and the
+// wired tables.go with its golden copy; run with -update to accept a change
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name       string
+		h2         string
+		typeName   string
+		outputName string
+	}{
+		{name: "testzombies", h2: "Zombies Match Data (reverse chronological)"},
+		{name: "testmatches", h2: "Match Data (reverse chronological)", typeName: "Game", outputName: "test_games"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg, err := loadPackage(filepath.Join("testdata", "export.html"), "Call of Duty: Test Game", tt.h2, tt.name, tt.typeName, tt.outputName, ".")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dir := t.TempDir()
+			if err := pkg.write(filepath.Join(dir, tt.name)); err != nil {
+				t.Fatal(err)
+			}
+			tables, err := os.ReadFile(filepath.Join("testdata", "tables.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "tables.go"), tables, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := wireTable(filepath.Join(dir, "tables.go"), pkg.Module+"/internal/datarequest/"+tt.name); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, file := range []string{
+				filepath.Join(tt.name, tt.name+".go"),
+				filepath.Join(tt.name, tt.name+"_test.go"),
+				filepath.Join(tt.name, "testdata", tt.name+".html"),
+				"tables.go",
+			} {
+				got, err := os.ReadFile(filepath.Join(dir, file))
+				if err != nil {
+					t.Fatal(err)
+				}
+				golden := filepath.Join("testdata", "golden", tt.name, filepath.Base(file)+".golden")
+				if *update {
+					if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(golden, got, 0o644); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != string(want) {
+					t.Errorf("generated %s differs from %s:\n%s", file, golden, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	// std
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"text/template"
+)
+
+// Executes a template against the package and writes it out gofmt'd
+func writeGo(fileName, tmpl string, p *tablePackage) error {
+	t, err := template.New(fileName).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if err := t.Execute(&b, p); err != nil {
+		return err
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("generated %s does not format: %w", fileName, err)
+	}
+	return os.WriteFile(fileName, src, 0o644)
+}
+
+// Matches the existing table packages, e.g. modernwarfarecoop
+const packageTemplate = `package {{.Name}}
+
+import (
+{{- if .HasDuration}}
+	// std
+	"time"
+
+	// internal
+{{- end}}
+	"{{.Module}}/internal/datarequest/registry"
+	"{{.Module}}/internal/helpers"
+)
+
+const (
+	h1Text = {{printf "%q" .H1}}
+	h2Text = {{printf "%q" .H2}}
+)
+
+// Identifies the table in reports
+const Name = {{printf "%q" .Name}}
+
+// Finds the table in an export
+{{if ne .H2Title .H2 -}}
+var Locator = helpers.NewLocator(h1Text, h2Text).
+	WithH2Patterns(helpers.QualifiedTitlePattern({{printf "%q" .H2Title}}))
+{{- else -}}
+var Locator = helpers.NewLocator(h1Text, h2Text)
+{{- end}}
+
+var fieldParsers = map[string]helpers.FieldParser{
+{{- range .Columns}}
+	{{printf "%q" .Label}}: {{.Parser}},
+{{- end}}
+}
+
+type {{.Type}} struct {
+{{- range .Columns}}
+	{{.Field}} {{.GoType}} ` + "`" + `col:{{printf "%q" .Label}} parquet:"{{.ParquetTag}}"` + "`" + `
+{{- end}}
+}
+
+type {{.Types}} []*{{.Type}}
+
+// Identifies the record across overlapping exports
+func (r *{{.Type}}) Key() string {
+	return helpers.NaturalKey({{range $i, $f := .Key}}{{if $i}}, {{end}}r.{{$f}}{{end}})
+}
+
+var binding = helpers.NewBinding[{{.Type}}](helpers.ColumnTag, fieldParsers)
+
+const outputName = {{printf "%q" .OutputName}}
+
+// Parses and writes out the table
+var Table = registry.Register(registry.NewTable(registry.Definition[{{.Type}}]{
+	Name:       Name,
+	Locator:    Locator,
+	Binding:    binding,
+	OutputName: outputName,
+}))
+`
+
+// Parses the fixture both ways the exports are read, checking the first record
+// field by field, and writes it out as CSV
+const testTemplate = `package {{.Name}}
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+{{- if .HasDuration}}
+	"time"
+{{- end}}
+
+	"github.com/PuerkitoBio/goquery"
+
+	"{{.Module}}/internal/datarequest/registry"
+	"{{.Module}}/internal/helpers"
+)
+
+const fixtureRows = {{.FixtureRows}}
+
+// The record the fixture's first row decodes to
+var wantFirst = &{{.Type}}{
+{{- range .First}}
+	{{.Field}}: {{.Value}},
+{{- end}}
+}
+
+func ptr[V any](v V) *V { return &v }
+
+func openFixture(t *testing.T) *os.File {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", Name+".html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func checkReport(t *testing.T, report helpers.TableReport) {
+	t.Helper()
+	if report.Drift.Any() {
+		t.Errorf("header drifted from {{.Type}}: %+v", report.Drift)
+	}
+	for _, reject := range report.Rejects {
+		t.Errorf("rejected row: %+v", reject)
+	}
+}
+
+// Compares the first record with the fixture's first row, field by field
+func checkFirst(t *testing.T, records []*{{.Type}}) {
+	t.Helper()
+	got := records[0]
+{{- range .First}}
+	if !reflect.DeepEqual(got.{{.Field}}, wantFirst.{{.Field}}) {
+		t.Errorf("{{.Field}} = %s, want %s", show(got.{{.Field}}), show(wantFirst.{{.Field}}))
+	}
+{{- end}}
+}
+
+// Formats a field's value, following a pointer
+func show(v any) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return "nil"
+		}
+		rv = rv.Elem()
+	}
+	return fmt.Sprintf("%#v", rv.Interface())
+}
+
+func TestParse(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(openFixture(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, report, err := Table.Parse(doc, helpers.TableOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkReport(t, report)
+	parsed := registry.RecordsOf[{{.Type}}](records)
+	if len(parsed) != fixtureRows {
+		t.Fatalf("parsed %d records, want %d", len(parsed), fixtureRows)
+	}
+	checkFirst(t, parsed)
+
+	dir := t.TempDir()
+	if err := Table.ToCSV(dir, records); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filepath.Join(dir, outputName+".csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != fixtureRows+1 {
+		t.Fatalf("wrote %d CSV rows, want a header and %d records", len(rows), fixtureRows)
+	}
+	for _, row := range rows {
+		if len(row) != len(Table.Header()) {
+			t.Fatalf("CSV row has %d cells, want %d", len(row), len(Table.Header()))
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	collector := Table.NewCollector(helpers.TableOptions{})
+	if err := helpers.StreamTables(openFixture(t), collector); err != nil {
+		t.Fatal(err)
+	}
+
+	records, report, err := collector.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkReport(t, report)
+	streamed := registry.RecordsOf[{{.Type}}](records)
+	if len(streamed) != fixtureRows {
+		t.Fatalf("streamed %d records, want %d", len(streamed), fixtureRows)
+	}
+	checkFirst(t, streamed)
+}
+`
@@ -0,0 +1,138 @@
+package coddata
+
+import (
+	// std
+	"context"
+	"fmt"
+	"io"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/datarequest"
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+	"github.com/hoodnoah/cod_data_request/internal/input"
+)
+
+// Controls how an export is parsed. The zero value parses every table, tolerating
+// missing sections and changed columns but no rows which fail to parse.
+type Options struct {
+	// fail if any selected table's section is missing from the export, rather than
+	// leaving the table empty with a warning in the report
+	Strict bool
+
+	// fail a table whose columns differ from its record type, rather than ignoring
+	// unknown columns and leaving fields of missing columns empty
+	StrictSchema bool
+
+	// how many of a table's rows may fail to parse, and be left out of its records
+	// and listed in the report, before the table fails: a count, negative for no
+	// limit, and a percentage of its rows, zero for no limit
+	MaxRejects       int
+	MaxRejectPercent float64
+
+	// the names of the tables to parse, e.g. TableWarzone2; every table if empty
+	Tables []string
+
+	// how many tables are parsed or written at once; one per CPU if not positive
+	Parallelism int
+
+	// read the export in a single pass rather than loading it into memory, for
+	// very large exports; tables are then parsed one at a time
+	Stream bool
+}
+
+func (o Options) parseOptions() datarequest.ParseOptions {
+	opts := datarequest.ParseOptions{
+		Lenient:     !o.Strict,
+		Budget:      helpers.ErrorBudget{MaxRows: o.MaxRejects, MaxPercent: o.MaxRejectPercent},
+		Parallelism: o.Parallelism,
+		Tables:      o.Tables,
+	}
+	if o.StrictSchema {
+		opts.Drift = helpers.DriftStrict
+	}
+	return opts
+}
+
+// The outcome of parsing an export, table by table: row counts, missing sections,
+// column drift and rejected rows
+type Report = datarequest.ParseReport
+
+// The outcome of parsing one table
+type TableStatus = datarequest.TableStatus
+
+// How a table's columns differ from its record type
+type HeaderDrift = helpers.HeaderDrift
+
+// A row which failed to parse, and was left out of its table's records
+type RowReject = helpers.RowReject
+
+// The records parsed from an export
+type Result struct {
+	request     datarequest.CodDataRequest
+	parallelism int
+
+	// what was parsed from each table
+	Report *Report
+}
+
+// Parses an export read from r: an HTML file, or the .zip archive Activision
+// delivers, either optionally gzip or zstd compressed, in any of the encodings
+// the exports have used. r is read once, and not closed.
+//
+// Parsing stops with ctx's error if ctx is done before the export has been read.
+// A table which fails fails the parse, returning every table's failure along with
+// the result, whose report describes each table.
+func Parse(ctx context.Context, r io.Reader, opts Options) (*Result, error) {
+	in, err := input.FromReader("export", contextReader{ctx, r})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	defer in.Close()
+
+	result := &Result{request: datarequest.NewCodDataRequest(), parallelism: opts.Parallelism}
+	if opts.Stream {
+		pr := in.Reader()
+		defer pr.Close()
+
+		result.Report, err = result.request.ParseReader(pr, opts.parseOptions())
+	} else {
+		doc, docErr := in.Document()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if docErr != nil {
+			return nil, docErr
+		}
+
+		result.Report, err = result.request.ParseHtml(doc, opts.parseOptions())
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return result, err
+}
+
+// Fails reads once its context is done, so that a parse reading from it stops
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// Writes each parsed table to <table output name>.csv in dir, which must exist.
+// Every table is written even if another fails; all failures are returned.
+func (r *Result) WriteCSV(dir string) error {
+	return r.request.ToCSV(dir, r.parallelism)
+}
+
+// Writes each parsed table to <table output name>.parquet in dir, which must
+// exist. Every table is written even if another fails; all failures are returned.
+func (r *Result) WriteParquet(dir string) error {
+	return r.request.ToParquet(dir, r.parallelism)
+}
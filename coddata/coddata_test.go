@@ -0,0 +1,115 @@
+package coddata_test
+
+import (
+	// std
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/coddata"
+)
+
+// Cancels its context once the first read has been served
+type cancellingReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+}
+
+func (c cancellingReader) Read(p []byte) (int, error) {
+	defer c.cancel()
+	return c.r.Read(p[:min(len(p), 64)])
+}
+
+func TestParse(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "export.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, stream := range []bool{false, true} {
+		parse := func(ctx context.Context, r io.Reader, opts coddata.Options) (*coddata.Result, error) {
+			opts.Stream = stream
+			return coddata.Parse(ctx, r, opts)
+		}
+		name := "DOM"
+		if stream {
+			name = "stream"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			t.Run("every table", func(t *testing.T) {
+				result, err := parse(context.Background(), bytes.NewReader(data), coddata.Options{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := len(result.Warzone2Matches()); got != 2 {
+					t.Errorf("%d Warzone 2.0 matches, want 2", got)
+				}
+				if got := len(result.ModernWarfareMultiplayerMatches()); got != 1 {
+					t.Errorf("%d Modern Warfare matches, want 1", got)
+				}
+				if result.ColdWarZombiesEvents() != nil {
+					t.Error("Cold War zombies events parsed from a missing section")
+				}
+				for _, status := range result.Report.Tables {
+					want := status.Table != coddata.TableWarzone2 && status.Table != coddata.TableModernWarfareMultiplayer
+					if status.Missing != want {
+						t.Errorf("%s missing %v, want %v", status.Table, status.Missing, want)
+					}
+				}
+			})
+
+			t.Run("selected tables", func(t *testing.T) {
+				result, err := parse(context.Background(), bytes.NewReader(data), coddata.Options{Tables: []string{coddata.TableWarzone2}})
+				if err != nil {
+					t.Fatal(err)
+				}
+				matches := result.Warzone2Matches()
+				if len(matches) != 2 || matches[0].Map != "Al Mazrah" || matches[0].Skill == nil || *matches[0].Skill != 1250 || matches[1].Skill != nil {
+					t.Errorf("Warzone 2.0 matches %+v, want Al Mazrah with skill 1250 then Ashika Island without", matches)
+				}
+				if result.ModernWarfareMultiplayerMatches() != nil {
+					t.Error("parsed a table which was not selected")
+				}
+				if len(result.Report.Tables) != 1 || result.Report.Tables[0].Table != coddata.TableWarzone2 {
+					t.Errorf("report %+v, want only %s", result.Report.Tables, coddata.TableWarzone2)
+				}
+			})
+
+			t.Run("unknown table", func(t *testing.T) {
+				if _, err := parse(context.Background(), bytes.NewReader(data), coddata.Options{Tables: []string{"warzone9"}}); err == nil {
+					t.Error("parsed an unknown table without error")
+				}
+			})
+
+			t.Run("strict", func(t *testing.T) {
+				if _, err := parse(context.Background(), bytes.NewReader(data), coddata.Options{Strict: true}); err == nil {
+					t.Error("strict parse succeeded with missing sections")
+				}
+			})
+
+			t.Run("cancelled", func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				result, err := parse(ctx, bytes.NewReader(data), coddata.Options{})
+				if !errors.Is(err, context.Canceled) || result != nil {
+					t.Errorf("result %v, error %v, want context.Canceled", result, err)
+				}
+			})
+
+			t.Run("cancelled while reading", func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				result, err := parse(ctx, cancellingReader{bytes.NewReader(data), cancel}, coddata.Options{})
+				if !errors.Is(err, context.Canceled) || result != nil {
+					t.Errorf("result %v, error %v, want context.Canceled", result, err)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// Package coddata parses the HTML export of an Activision Call of Duty data
+// request into typed records, one table per game mode, and writes them to CSV or
+// parquet, as cmd/ingest does, for programs which embed the parser:
+//
+//	f, err := os.Open("export.zip")
+//	...
+//	result, err := coddata.Parse(ctx, f, coddata.Options{MaxRejects: -1})
+//	...
+//	for _, match := range result.Warzone2Matches() {
+//		fmt.Println(match.Map, match.Timestamp)
+//	}
+//	err = result.WriteParquet("out")
+//
+// # Compatibility
+//
+// The API follows semantic versioning, and Version names the version of the API
+// this copy of the package provides. Within a major version:
+//
+//   - exported identifiers are not removed or renamed, and function signatures
+//     do not change; fields and methods may be added
+//   - the zero value of Options keeps its meaning; new options default to the
+//     existing behaviour
+//   - record fields are not removed, renamed or retyped; fields may be added as
+//     Activision adds columns
+//   - table names, output file names and CSV and parquet column names do not
+//     change, so output written by one minor version can be appended to by
+//     another
+//
+// Row order within a table follows the export. The text of errors and report
+// warnings is not covered, and may change in any release.
+//
+// Everything under internal/ is an implementation detail; the record types are
+// aliases of internal types so that their fields can be used directly, but the
+// packages declaring them must not be relied upon.
+package coddata
+
+// The version of the coddata API, following semantic versioning
+const Version = "1.0.0"
@@ -0,0 +1,45 @@
+package coddata_test
+
+import (
+	// std
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/coddata"
+)
+
+func ExampleParse() {
+	f, err := os.Open(filepath.Join("testdata", "export.html"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	result, err := coddata.Parse(context.Background(), f, coddata.Options{
+		Tables: []string{coddata.TableWarzone2},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, match := range result.Warzone2Matches() {
+		skill := "-"
+		if match.Skill != nil {
+			skill = fmt.Sprint(*match.Skill)
+		}
+		played := time.UnixMilli(match.Timestamp).UTC().Format(time.DateTime)
+		fmt.Println(played, match.Map, match.MatchOutcome, skill)
+	}
+	for _, status := range result.Report.Tables {
+		fmt.Printf("%s: %d rows, %d rejected\n", status.Table, status.Rows, len(status.Rejects))
+	}
+	// Output:
+	// 2024-02-10 21:05:00 Al Mazrah win 1250
+	// 2024-02-09 20:00:00 Ashika Island loss -
+	// warzone2: 2 rows, 0 rejected
+}
@@ -0,0 +1,72 @@
+package coddata
+
+import (
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/blops6campaign"
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/blops6multiplayer"
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/coldwarzombies"
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/modernwarfarecampaign"
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/modernwarfarecoop"
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/modernwarfaremultiplayer"
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/registry"
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/warzone2"
+)
+
+// The names of the tables, as selected by Options.Tables and named in the report
+const (
+	TableBlackOps6Campaign        = blops6campaign.Name
+	TableBlackOps6Multiplayer     = blops6multiplayer.Name
+	TableColdWarZombies           = coldwarzombies.Name
+	TableModernWarfareCampaign    = modernwarfarecampaign.Name
+	TableModernWarfareCoop        = modernwarfarecoop.Name
+	TableModernWarfareMultiplayer = modernwarfaremultiplayer.Name
+	TableWarzone2                 = warzone2.Name
+)
+
+// The record types of each table. A nil pointer field is a cell the export left
+// empty; timestamps are unix milliseconds, in UTC.
+type (
+	BlackOps6CampaignCheckpoint   = blops6campaign.Checkpoint
+	BlackOps6MultiplayerMatch     = blops6multiplayer.MultiplayerMatch
+	ColdWarZombiesEvent           = coldwarzombies.ColdWarZombiesEvent
+	ModernWarfareCampaignSegment  = modernwarfarecampaign.ModernWarfareCampaignSegment
+	ModernWarfareCoopMatch        = modernwarfarecoop.ModernWafareCoop
+	ModernWarfareMultiplayerMatch = modernwarfaremultiplayer.MWMultiplayerMatch
+	Warzone2Match                 = warzone2.Warzone2Match
+)
+
+// Returns the records of the Black Ops 6 campaign table, in export order; nil if
+// the table was not parsed or its section was missing
+func (r *Result) BlackOps6CampaignCheckpoints() []*BlackOps6CampaignCheckpoint {
+	return registry.RecordsOf[BlackOps6CampaignCheckpoint](r.request.Records(TableBlackOps6Campaign))
+}
+
+// Returns the records of the Black Ops 6 multiplayer table
+func (r *Result) BlackOps6MultiplayerMatches() []*BlackOps6MultiplayerMatch {
+	return registry.RecordsOf[BlackOps6MultiplayerMatch](r.request.Records(TableBlackOps6Multiplayer))
+}
+
+// Returns the records of the Cold War zombies table
+func (r *Result) ColdWarZombiesEvents() []*ColdWarZombiesEvent {
+	return registry.RecordsOf[ColdWarZombiesEvent](r.request.Records(TableColdWarZombies))
+}
+
+// Returns the records of the Modern Warfare campaign table
+func (r *Result) ModernWarfareCampaignSegments() []*ModernWarfareCampaignSegment {
+	return registry.RecordsOf[ModernWarfareCampaignSegment](r.request.Records(TableModernWarfareCampaign))
+}
+
+// Returns the records of the Modern Warfare co-op table
+func (r *Result) ModernWarfareCoopMatches() []*ModernWarfareCoopMatch {
+	return registry.RecordsOf[ModernWarfareCoopMatch](r.request.Records(TableModernWarfareCoop))
+}
+
+// Returns the records of the Modern Warfare multiplayer table
+func (r *Result) ModernWarfareMultiplayerMatches() []*ModernWarfareMultiplayerMatch {
+	return registry.RecordsOf[ModernWarfareMultiplayerMatch](r.request.Records(TableModernWarfareMultiplayer))
+}
+
+// Returns the records of the Warzone 2.0 table
+func (r *Result) Warzone2Matches() []*Warzone2Match {
+	return registry.RecordsOf[Warzone2Match](r.request.Records(TableWarzone2))
+}
@@ -0,0 +1,102 @@
+package datarequest
+
+import (
+	// std
+	"errors"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/registry"
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+)
+
+var errAppendUnrecognized = errors.New("unrecognized tables cannot be appended to existing output")
+
+// The outcome of appending one table to its existing output
+type AppendStatus struct {
+	Table string `json:"table"`
+	File  string `json:"file"`
+
+	// the newest timestamp already written, in unix milliseconds, if any output existed
+	Since    int64 `json:"since_ms"`
+	Existing bool  `json:"existing"`
+
+	Written int `json:"written"`
+	Skipped int `json:"skipped"`
+}
+
+// Describes the outcome of appending an export to existing output, table by table
+type AppendReport struct {
+	Tables []AppendStatus `json:"tables"`
+}
+
+// Appends checked against every table's existing output, ready to be written
+type PendingAppend struct {
+	report      AppendReport
+	plans       []*helpers.AppendPlan
+	parallelism int
+}
+
+// Writes every planned append, returning what was written
+func (p *PendingAppend) Write() (AppendReport, error) {
+	tasks := make([]func() error, len(p.plans))
+	for i, plan := range p.plans {
+		tasks[i] = plan.Write
+	}
+	return p.report, runBounded(p.parallelism, tasks)
+}
+
+// Plans appending the rows newer than those already in each table's CSV in the
+// output directory. Every file is checked before anything is written, so a header
+// which no longer matches fails the plan and leaves the directory untouched.
+func (c *CodDataRequest) PlanCSVAppend(outputDir string, parallelism int) (*PendingAppend, error) {
+	return c.planAppend(parallelism, func(table registry.Table, records registry.Records) (*helpers.AppendPlan, error) {
+		return table.PlanCSVAppend(outputDir, records)
+	})
+}
+
+// Plans writing the rows newer than those already in each table's parquet output as
+// a new part file. Every file is checked before anything is written, so a schema
+// which no longer matches fails the plan and leaves the directory untouched.
+func (c *CodDataRequest) PlanParquetAppend(outputDir string, parallelism int) (*PendingAppend, error) {
+	return c.planAppend(parallelism, func(table registry.Table, records registry.Records) (*helpers.AppendPlan, error) {
+		return table.PlanParquetAppend(outputDir, records)
+	})
+}
+
+// Plans every table's append, failing if any table's existing output cannot be
+// appended to
+func (c *CodDataRequest) planAppend(
+	parallelism int,
+	plan func(registry.Table, registry.Records) (*helpers.AppendPlan, error),
+) (*PendingAppend, error) {
+	if len(c.Unrecognized) > 0 {
+		return nil, errAppendUnrecognized
+	}
+
+	tables := c.tables()
+	plans := make([]*helpers.AppendPlan, len(tables))
+	planTasks := make([]func() error, len(tables))
+	for i, table := range tables {
+		records := c.records[table.Name()]
+		planTasks[i] = func() (err error) {
+			plans[i], err = plan(table, records)
+			return err
+		}
+	}
+	if err := runBounded(parallelism, planTasks); err != nil {
+		return nil, err
+	}
+
+	pending := &PendingAppend{plans: plans, parallelism: parallelism}
+	for i, plan := range plans {
+		pending.report.Tables = append(pending.report.Tables, AppendStatus{
+			Table:    tables[i].Name(),
+			File:     plan.FileName,
+			Since:    plan.Since,
+			Existing: plan.Existing,
+			Written:  plan.Rows,
+			Skipped:  plan.Skipped,
+		})
+	}
+	return pending, nil
+}
@@ -0,0 +1,171 @@
+package blops6multiplayer
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+)
+
+// The number of rows in the benchmark fixture, about as many as a heavy player's export
+const benchmarkRows = 10_000
+
+// Builds a header of every column of MultiplayerMatch, in field order, and rows of
+// plausible cells for each; nullable columns are left empty in every tenth row.
+func fixture(rows int) ([]string, [][]string) {
+	t := reflect.TypeFor[MultiplayerMatch]()
+	var header []string
+	for i := range t.NumField() {
+		header = append(header, t.Field(i).Tag.Get(helpers.ColumnTag))
+	}
+
+	start := time.Date(2024, 10, 25, 0, 0, 0, 0, time.UTC)
+	table := make([][]string, rows)
+	for r := range table {
+		ts := start.Add(time.Duration(r) * 17 * time.Minute).Format("2006-01-02 15:04:05")
+		row := make([]string, len(header))
+		for i := range header {
+			field := t.Field(i)
+			switch {
+			case field.Type.Kind() == reflect.Pointer && r%10 == 9:
+				row[i] = ""
+			case field.Type.Kind() == reflect.String:
+				row[i] = fmt.Sprintf("%s %d", field.Name, r%7)
+			case field.Type == reflect.TypeFor[*time.Duration]():
+				row[i] = fmt.Sprintf("%d:%02d:%02d", r%200, r%60, r%59)
+			case field.Type == reflect.TypeFor[*float64]():
+				row[i] = fmt.Sprintf("%d.%d", r%100, r%10)
+			case strings.Contains(field.Tag.Get("parquet"), "TIMESTAMP_MILLIS"):
+				row[i] = ts
+			default:
+				row[i] = fmt.Sprintf("%d", r*31%100_000)
+			}
+		}
+		table[r] = row
+	}
+	return header, table
+}
+
+func TestFixtureParses(t *testing.T) {
+	header, rows := fixture(100)
+	if len(header) != 62 {
+		t.Fatalf("fixture has %d columns, want 62", len(header))
+	}
+
+	binder, err := binding.Bind(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, row := range rows {
+		bound, err := binder.Parse(row)
+		if err != nil {
+			t.Fatalf("row %d: %v", i, err)
+		}
+		reflected, err := helpers.ParseRowReflect[MultiplayerMatch](header, row, helpers.ColumnTag, fieldParsers)
+		if err != nil {
+			t.Fatalf("row %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(bound, reflected) {
+			t.Fatalf("row %d: bound %+v, reflected %+v", i, bound, reflected)
+		}
+	}
+}
+
+// Numbers beyond 32 bits and doubles read back from parquet exactly as written
+func TestParquetRoundTrip(t *testing.T) {
+	header, rows := fixture(10)
+	binder, err := binding.Bind(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []*MultiplayerMatch
+	for _, row := range rows {
+		record, err := binder.Parse(row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	totalXP, moving := int64(3_000_000_000), 12.345678901234567
+	records[0].TotalXP, records[0].PercentageOfTimeMoving = &totalXP, &moving
+
+	fileName := filepath.Join(t.TempDir(), outputName+".parquet")
+	if err := helpers.ToParquet(fileName, records, new(MultiplayerMatch)); err != nil {
+		t.Fatal(err)
+	}
+
+	pf, err := local.NewLocalFileReader(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pf.Close()
+	pr, err := reader.NewParquetReader(pf, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.ReadStop()
+
+	// the struct is flat, so each field is the leaf column of the same index
+	typ := reflect.TypeFor[MultiplayerMatch]()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if field.Type != reflect.TypeFor[*int64]() && field.Type != reflect.TypeFor[*float64]() {
+			continue
+		}
+		values, _, _, err := pr.ReadColumnByIndex(int64(i), pr.GetNumRows())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(values) != len(records) {
+			t.Fatalf("%s: read %d values, want %d", field.Name, len(values), len(records))
+		}
+		for r, record := range records {
+			want := reflect.ValueOf(record).Elem().Field(i)
+			got := values[r]
+			if want.IsNil() != (got == nil) || !want.IsNil() && got != want.Elem().Interface() {
+				t.Errorf("%s of row %d read back as %v (%T), want %v", field.Name, r, got, got, want.Elem())
+			}
+		}
+	}
+}
+
+// Parses every row with the header bound once, as tables do
+func BenchmarkBinding(b *testing.B) {
+	header, rows := fixture(benchmarkRows)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		binder, err := binding.Bind(header)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, row := range rows {
+			if _, err := binder.Parse(row); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*benchmarkRows), "ns/row")
+}
+
+// Parses every row with ParseRowReflect, resolving the header for each
+func BenchmarkParseRowReflect(b *testing.B) {
+	header, rows := fixture(benchmarkRows)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		for _, row := range rows {
+			if _, err := helpers.ParseRowReflect[MultiplayerMatch](header, row, helpers.ColumnTag, fieldParsers); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*benchmarkRows), "ns/row")
+}
@@ -1,150 +1,267 @@
 package datarequest
 
 import (
+	// std
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
 	// external
 	"github.com/PuerkitoBio/goquery"
 
 	// internal
-	blops "github.com/hoodnoah/cod_data_request/internal/datarequest/blops6campaign"
-	blopsMP "github.com/hoodnoah/cod_data_request/internal/datarequest/blops6multiplayer"
-	cwZombies "github.com/hoodnoah/cod_data_request/internal/datarequest/coldwarzombies"
-	mwCampaign "github.com/hoodnoah/cod_data_request/internal/datarequest/modernwarfarecampaign"
-	mwCoop "github.com/hoodnoah/cod_data_request/internal/datarequest/modernwarfarecoop"
-	mwMp "github.com/hoodnoah/cod_data_request/internal/datarequest/modernwarfaremultiplayer"
-	wz2Mp "github.com/hoodnoah/cod_data_request/internal/datarequest/warzone2"
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/registry"
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
 )
 
 type CodDataRequest struct {
-	BlackOps6CampaignCheckpoints  blops.Checkpoints
-	BlackOps6MultiplayerMatches   blopsMP.MultiplayerMatches
-	ColdWarZombiesEvents          cwZombies.ColdWarZombiesEvents
-	ModernWarfareCampaignSegments mwCampaign.ModernWarfareCampaignSegments
-	ModernWarfareCoops            mwCoop.ModernWarfareCoops
-	ModernWarfareMPMatches        mwMp.MWMultiplayerMatches
-	Warzone2MPMatches             wz2Mp.Warzone2Matches
+	// the records of each registered table, by table name
+	records map[string]registry.Records
+
+	// tables without a registered handler, kept when parsing with Unrecognized set
+	Unrecognized []*helpers.GenericTable
 }
 
-func NewCodDataRequest() CodDataRequest {
-	return CodDataRequest{
-		BlackOps6CampaignCheckpoints:  nil,
-		BlackOps6MultiplayerMatches:   nil,
-		ColdWarZombiesEvents:          nil,
-		ModernWarfareCampaignSegments: nil,
-		ModernWarfareCoops:            nil,
-		ModernWarfareMPMatches:        nil,
-		Warzone2MPMatches:             nil,
-	}
+// Controls how an export is parsed
+type ParseOptions struct {
+	// leave tables whose section is missing empty, noting them in the report,
+	// instead of failing the whole parse
+	Lenient bool
+
+	// also extract every table with no registered handler, inferring its schema
+	Unrecognized bool
+
+	// how many rows each table may reject before its parse fails
+	Budget helpers.ErrorBudget
+
+	// how a header which differs from the table's struct is handled
+	Drift helpers.DriftPolicy
+
+	// how many tables ParseHtml parses at once; one per CPU if not positive
+	Parallelism int
+
+	// the names of the tables to parse; every registered table if empty. Tables
+	// not selected are left out of the report and the output.
+	Tables []string
 }
 
-// Reads all data record types from a provided HTML file
-func (c *CodDataRequest) ParseHtml(doc *goquery.Document) error {
-	blops6campaignCheckpoints, err := blops.FromHtml(doc)
-	if err != nil {
-		return err
-	}
-	c.BlackOps6CampaignCheckpoints = blops6campaignCheckpoints
+func (o ParseOptions) tableOptions() helpers.TableOptions {
+	return helpers.TableOptions{Budget: o.Budget, Drift: o.Drift}
+}
 
-	blops6MPMatches, err := blopsMP.FromHtml(doc)
-	if err != nil {
-		return err
+// Returns the registered tables selected by opts.Tables, in registration order,
+// failing if any name is not a registered table
+func (o ParseOptions) selectedTables() ([]registry.Table, error) {
+	tables := registry.Tables()
+	if len(o.Tables) == 0 {
+		return tables, nil
 	}
-	c.BlackOps6MultiplayerMatches = blops6MPMatches
 
-	cwZombiesEvents, err := cwZombies.FromHtml(doc)
-	if err != nil {
-		return err
+	for _, name := range o.Tables {
+		if _, ok := registry.Lookup(name); !ok {
+			return nil, fmt.Errorf("unknown table %q", name)
+		}
 	}
-	c.ColdWarZombiesEvents = cwZombiesEvents
+	return slices.DeleteFunc(tables, func(t registry.Table) bool { return !slices.Contains(o.Tables, t.Name()) }), nil
+}
 
-	mwCampaignEvents, err := mwCampaign.FromHtml(doc)
-	if err != nil {
-		return err
+func NewCodDataRequest() CodDataRequest {
+	return CodDataRequest{
+		records:      make(map[string]registry.Records),
+		Unrecognized: nil,
 	}
-	c.ModernWarfareCampaignSegments = mwCampaignEvents
+}
 
-	mwCoop, err := mwCoop.FromHtml(doc)
-	if err != nil {
-		return err
-	}
-	c.ModernWarfareCoops = mwCoop
+// Returns the records parsed for the named table, or nil if it has none; read them
+// with registry.RecordsOf and the table's record type
+func (c *CodDataRequest) Records(table string) registry.Records {
+	return c.records[table]
+}
 
-	mwMpmatches, err := mwMp.FromHtml(doc)
-	if err != nil {
-		return err
+func (c *CodDataRequest) setRecords(table string, records registry.Records) {
+	if c.records == nil {
+		c.records = make(map[string]registry.Records)
 	}
-	c.ModernWarfareMPMatches = mwMpmatches
+	c.records[table] = records
+}
+
+// Returns the registered tables which were parsed or merged into the request, in
+// registration order; these are the tables written out
+func (c *CodDataRequest) tables() []registry.Table {
+	return slices.DeleteFunc(registry.Tables(), func(t registry.Table) bool {
+		_, ok := c.records[t.Name()]
+		return !ok
+	})
+}
 
-	wz2Mpmatches, err := wz2Mp.FromHtml(doc)
+// Reads all data record types from a provided HTML file, parsing up to
+// opts.Parallelism tables at once.
+// In lenient mode, a table whose section is missing is left empty and noted in the
+// report; any other parse failure is returned regardless of mode. Every table is
+// parsed even if another fails, and all failures are returned, joined in table order.
+func (c *CodDataRequest) ParseHtml(doc *goquery.Document, opts ParseOptions) (*ParseReport, error) {
+	report := &ParseReport{}
+	tableOpts := opts.tableOptions()
+
+	tables, err := opts.selectedTables()
 	if err != nil {
-		return err
+		return report, err
+	}
+	results := make([]tableResult, len(tables))
+	tasks := make([]func() error, len(tables))
+	for i, table := range tables {
+		tasks[i] = func() error {
+			records, tableReport, err := table.Parse(doc, tableOpts)
+			results[i] = tableResult{records: records, report: tableReport, err: err}
+			return nil
+		}
+	}
+	if opts.Unrecognized {
+		tasks = append(tasks, func() error { return c.parseUnrecognized(doc) })
 	}
-	c.Warzone2MPMatches = wz2Mpmatches
 
-	return nil
+	// parse tasks only fail outright for unrecognized tables; known tables are
+	// judged by the report, in order, once all have finished
+	runErr := runBounded(opts.Parallelism, tasks)
+
+	errs := c.addResults(report, tables, results, opts)
+	errs = append(errs, runErr)
+
+	return report, errors.Join(errs...)
 }
 
-// saves constituent data records to CSV, failing on the first error
-func (c *CodDataRequest) ToCSV(outputDir string) error {
-	if err := blops.ToCSV(outputDir, c.BlackOps6CampaignCheckpoints); err != nil {
-		return err
-	}
+// The outcome of parsing one known table, before it is added to the report
+type tableResult struct {
+	records registry.Records
+	report  helpers.TableReport
+	err     error
+}
 
-	if err := blopsMP.ToCSV(outputDir, &c.BlackOps6MultiplayerMatches); err != nil {
-		return err
+// Stores each table's records and adds its outcome to the report, in table order,
+// returning the errors which should stop parsing
+func (c *CodDataRequest) addResults(report *ParseReport, tables []registry.Table, results []tableResult, opts ParseOptions) []error {
+	var errs []error
+	for i, table := range tables {
+		result := results[i]
+		rows := 0
+		if result.records != nil {
+			rows = result.records.Len()
+		}
+		c.setRecords(table.Name(), result.records)
+		errs = append(errs, report.add(table.Name(), rows, result.report, result.err, opts))
 	}
+	return errs
+}
 
-	if err := cwZombies.ToCSV(outputDir, &c.ColdWarZombiesEvents); err != nil {
-		return err
-	}
+// Extracts every table not claimed by a table package as a generic table
+func (c *CodDataRequest) parseUnrecognized(doc *goquery.Document) error {
+	var tables []*helpers.GenericTable
+	for _, section := range classifyTables(doc) {
+		if section.name != "" || section.shape.Table == nil {
+			continue
+		}
 
-	if err := mwCampaign.ToCSV(outputDir, &c.ModernWarfareCampaignSegments); err != nil {
-		return err
+		header, rows, err := helpers.ReadTable(section.shape.Table)
+		if err != nil {
+			return fmt.Errorf("unrecognized table under H1 %s, H2 %s: %w", section.shape.H1, section.shape.H2, err)
+		}
+		tables = append(tables, helpers.NewGenericTable(section.shape.H1, section.shape.H2, header, rows))
 	}
 
-	if err := mwCoop.ToCSV(outputDir, &c.ModernWarfareCoops); err != nil {
-		return err
-	}
+	c.setUnrecognized(tables)
+	return nil
+}
 
-	if err := mwMp.ToCSV(outputDir, &c.ModernWarfareMPMatches); err != nil {
-		return err
+// Stores the generic tables, disambiguating any which would share an output name
+func (c *CodDataRequest) setUnrecognized(tables []*helpers.GenericTable) {
+	seen := make(map[string]bool)
+	for _, table := range registry.Tables() {
+		seen[table.Name()] = true
 	}
-
-	if err := wz2Mp.ToCSV(outputDir, &c.Warzone2MPMatches); err != nil {
-		return err
+	for _, table := range tables {
+		name := table.Name
+		for n := 2; seen[name]; n++ {
+			name = fmt.Sprintf("%s_%d", table.Name, n)
+		}
+		table.Name = name
+		seen[name] = true
 	}
-
-	return nil
+	c.Unrecognized = tables
 }
 
-// saves constituent data records to parquet, failing on the first error
-func (c *CodDataRequest) ToParquet(outputDir string) error {
-	if err := blops.ToParquet(outputDir, c.BlackOps6CampaignCheckpoints); err != nil {
-		return err
+// Reads all data record types in a single streaming pass over the provided HTML,
+// without holding the document in memory. Produces the same records as ParseHtml;
+// being a single pass, it ignores opts.Parallelism.
+func (c *CodDataRequest) ParseReader(r io.Reader, opts ParseOptions) (*ParseReport, error) {
+	report := &ParseReport{}
+	tableOpts := opts.tableOptions()
+
+	tables, err := opts.selectedTables()
+	if err != nil {
+		return report, err
+	}
+	collectors := make([]registry.Collector, len(tables))
+	sinks := make([]helpers.TableSink, len(tables))
+	for i, table := range tables {
+		collectors[i] = table.NewCollector(tableOpts)
+		sinks[i] = collectors[i]
 	}
 
-	if err := blopsMP.ToParquet(outputDir, &c.BlackOps6MultiplayerMatches); err != nil {
-		return err
+	// placed last, so it only receives tables no package claims
+	unrecognized := &helpers.GenericCollector{}
+	if opts.Unrecognized {
+		sinks = append(sinks, unrecognized)
 	}
 
-	if err := cwZombies.ToParquet(outputDir, &c.ColdWarZombiesEvents); err != nil {
-		return err
+	if err := helpers.StreamTables(r, sinks...); err != nil {
+		return report, err
 	}
 
-	if err := mwCampaign.ToParquet(outputDir, &c.ModernWarfareCampaignSegments); err != nil {
-		return err
+	results := make([]tableResult, len(tables))
+	for i, collector := range collectors {
+		records, tableReport, err := collector.Result()
+		results[i] = tableResult{records: records, report: tableReport, err: err}
 	}
+	errs := c.addResults(report, tables, results, opts)
 
-	if err := mwCoop.ToParquet(outputDir, &c.ModernWarfareCoops); err != nil {
-		return err
+	if opts.Unrecognized {
+		tables, err := unrecognized.Result()
+		if err == nil {
+			c.setUnrecognized(tables)
+		}
+		errs = append(errs, err)
 	}
 
-	if err := mwMp.ToParquet(outputDir, &c.ModernWarfareMPMatches); err != nil {
-		return err
+	return report, errors.Join(errs...)
+}
+
+// saves constituent data records to CSV, writing up to parallelism files at once.
+// Every table is written even if another fails; all failures are returned, joined.
+func (c *CodDataRequest) ToCSV(outputDir string, parallelism int) error {
+	var tasks []func() error
+	for _, table := range c.tables() {
+		records := c.records[table.Name()]
+		tasks = append(tasks, func() error { return table.ToCSV(outputDir, records) })
+	}
+	for _, table := range c.Unrecognized {
+		tasks = append(tasks, func() error { return table.ToCSV(outputDir) })
 	}
 
-	if err := wz2Mp.ToParquet(outputDir, &c.Warzone2MPMatches); err != nil {
-		return err
+	return runBounded(parallelism, tasks)
+}
+
+// saves constituent data records to parquet, writing up to parallelism files at once.
+// Every table is written even if another fails; all failures are returned, joined.
+func (c *CodDataRequest) ToParquet(outputDir string, parallelism int) error {
+	var tasks []func() error
+	for _, table := range c.tables() {
+		records := c.records[table.Name()]
+		tasks = append(tasks, func() error { return table.ToParquet(outputDir, records) })
+	}
+	for _, table := range c.Unrecognized {
+		tasks = append(tasks, func() error { return table.ToParquet(outputDir) })
 	}
 
-	return nil
+	return runBounded(parallelism, tasks)
 }
@@ -0,0 +1,82 @@
+package datarequest
+
+import (
+	// std
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	// external
+	"github.com/PuerkitoBio/goquery"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/registry"
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+)
+
+// ParseReader produces the same records, unrecognized tables and report as
+// ParseHtml for an export with spanned cells, header and footer rows, nested
+// tables and a section no table package claims
+func TestParseReaderMatchesParseHtml(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "export.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts ParseOptions
+	}{
+		{name: "lenient", opts: ParseOptions{Lenient: true, Unrecognized: true, Budget: helpers.ErrorBudget{MaxRows: -1}}},
+		{name: "strict", opts: ParseOptions{Unrecognized: true, Drift: helpers.DriftStrict}},
+		{name: "selected", opts: ParseOptions{Tables: []string{"blops6multiplayer"}, Budget: helpers.ErrorBudget{MaxRows: -1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dom := NewCodDataRequest()
+			domReport, domErr := dom.ParseHtml(doc, tt.opts)
+			stream := NewCodDataRequest()
+			streamReport, streamErr := stream.ParseReader(bytes.NewReader(data), tt.opts)
+
+			if (domErr == nil) != (streamErr == nil) || domErr != nil && domErr.Error() != streamErr.Error() {
+				t.Errorf("ParseHtml error %v, ParseReader error %v", domErr, streamErr)
+			}
+			if !reflect.DeepEqual(domReport, streamReport) {
+				t.Errorf("ParseHtml report %+v\nParseReader report %+v", domReport, streamReport)
+			}
+			if !reflect.DeepEqual(dom.records, stream.records) {
+				t.Error("ParseHtml and ParseReader parsed different records")
+			}
+			if !reflect.DeepEqual(dom.Unrecognized, stream.Unrecognized) {
+				t.Errorf("ParseHtml unrecognized %+v\nParseReader unrecognized %+v", dom.Unrecognized, stream.Unrecognized)
+			}
+		})
+	}
+}
+
+// Every table writes its numbers to parquet at full width, as they are parsed
+func TestParquetTypes(t *testing.T) {
+	want := map[reflect.Type]string{
+		reflect.TypeFor[int64]():    "type=INT64",
+		reflect.TypeFor[*int64]():   "type=INT64",
+		reflect.TypeFor[float64]():  "type=DOUBLE",
+		reflect.TypeFor[*float64](): "type=DOUBLE",
+	}
+	for _, table := range registry.Tables() {
+		typ := reflect.TypeOf(table.Schema()).Elem()
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			if w, ok := want[field.Type]; ok && !strings.Contains(field.Tag.Get("parquet"), w) {
+				t.Errorf("%s.%s is written as %q, want %s", table.Name(), field.Name, field.Tag.Get("parquet"), w)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package datarequest
+
+import (
+	// std
+	"fmt"
+
+	// external
+	"github.com/PuerkitoBio/goquery"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/registry"
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+)
+
+// Extends the headings each named table accepts, e.g. with overrides read by
+// helpers.LoadLocatorOverrides. Fails on a name which matches no known table.
+func ExtendHeadings(overrides map[string]helpers.LocatorOverride) error {
+	for name, override := range overrides {
+		table, ok := registry.Lookup(name)
+		if !ok {
+			return fmt.Errorf("heading override for unknown table %q", name)
+		}
+		if err := table.Locator().Extend(override); err != nil {
+			return fmt.Errorf("heading override for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Summarizes one section of an export, and the package which parses it, if any
+type SectionSummary struct {
+	H1        string   `json:"h1"`
+	H2        string   `json:"h2"`
+	Rows      int      `json:"rows"`
+	Columns   int      `json:"columns"`
+	Header    []string `json:"header,omitempty"`
+	Table     string   `json:"table,omitempty"`
+	Supported bool     `json:"supported"`
+}
+
+// Lists every section and table in the document, marking which are parsed by a
+// known table package and which are unsupported.
+func Inspect(doc *goquery.Document) []SectionSummary {
+	var summaries []SectionSummary
+	for _, section := range classifyTables(doc) {
+		summaries = append(summaries, SectionSummary{
+			H1:        section.shape.H1,
+			H2:        section.shape.H2,
+			Rows:      section.shape.Rows,
+			Columns:   section.shape.Columns,
+			Header:    section.shape.Header,
+			Table:     section.name,
+			Supported: section.name != "",
+		})
+	}
+	return summaries
+}
+
+// A table found in an export, and the name of the known table which claims it, if any
+type classifiedTable struct {
+	shape helpers.TableShape
+	name  string
+}
+
+func classifyTables(doc *goquery.Document) []classifiedTable {
+	claimed := make(map[string]bool)
+
+	var sections []classifiedTable
+	for _, shape := range helpers.ListTables(doc) {
+		section := classifiedTable{shape: shape}
+
+		// the first table under matching headings is the table's, even if it cannot
+		// be read, as in FindTableAfterHeaders
+		if shape.Table != nil {
+			if name, ok := matchKnownTable(shape.H1, shape.H2); ok && !claimed[name] {
+				claimed[name] = true
+				section.name = name
+			}
+		}
+
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+func matchKnownTable(h1, h2 string) (string, bool) {
+	for _, table := range registry.Tables() {
+		if table.Locator().Matches(h1, h2) {
+			return table.Name(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,53 @@
+package datarequest
+
+import (
+	// std
+	"reflect"
+	"strings"
+	"testing"
+
+	// external
+	"github.com/PuerkitoBio/goquery"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/warzone2"
+)
+
+// A known section is claimed by its headings, whether or not its table can be
+// read, so it is never also extracted as an unrecognized table
+func TestClassifyByHeadings(t *testing.T) {
+	const headings = "<h1>Call of Duty: Warzone 2.0</h1><h2>Multiplayer Match Data (reverse chronological)</h2>"
+	tables := []struct {
+		name  string
+		table string
+	}{
+		{name: "empty", table: "<table></table>"},
+		{name: "no header", table: "<table><tr><td>1</td></tr></table>"},
+		{name: "header only", table: "<table><tr><th>UTC Timestamp</th></tr></table>"},
+	}
+	for _, tt := range tables {
+		t.Run(tt.name, func(t *testing.T) {
+			export := headings + tt.table + "<h2>Other Data</h2><table><tr><th>A</th></tr><tr><td>1</td></tr></table>"
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(export))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var claimed []string
+			for _, section := range Inspect(doc) {
+				claimed = append(claimed, section.Table)
+			}
+			if want := []string{warzone2.Name, ""}; !reflect.DeepEqual(claimed, want) {
+				t.Errorf("sections claimed by %q, want %q", claimed, want)
+			}
+
+			c := NewCodDataRequest()
+			if err := c.parseUnrecognized(doc); err != nil {
+				t.Fatal(err)
+			}
+			if len(c.Unrecognized) != 1 || c.Unrecognized[0].H2 != "Other Data" {
+				t.Errorf("unrecognized %+v, want only Other Data", c.Unrecognized)
+			}
+		})
+	}
+}
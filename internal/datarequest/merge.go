@@ -0,0 +1,83 @@
+package datarequest
+
+import (
+	// std
+	"slices"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/registry"
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+)
+
+// How many of a table's rows in a merged export were new, and how many were
+// already present
+type MergeStatus struct {
+	Table       string `json:"table"`
+	New         int    `json:"new"`
+	Overlapping int    `json:"overlapping"`
+}
+
+// Describes the outcome of merging one export into another, table by table
+type MergeReport struct {
+	Tables []MergeStatus `json:"tables"`
+}
+
+// Returns the number of new and overlapping rows across every table
+func (r MergeReport) Totals() (added, overlapping int) {
+	for _, status := range r.Tables {
+		added += status.New
+		overlapping += status.Overlapping
+	}
+	return added, overlapping
+}
+
+func (r *MergeReport) add(table string, added, overlapping int) {
+	r.Tables = append(r.Tables, MergeStatus{Table: table, New: added, Overlapping: overlapping})
+}
+
+// Merges another export's records into this one, table by table. Records already
+// present, by each table's natural key, are dropped; the rest are appended in the
+// other export's order. Tables neither export parsed are skipped. Unrecognized
+// tables are merged with the table of the same headings and header, comparing
+// whole rows, or kept alongside it otherwise.
+func (c *CodDataRequest) Merge(other *CodDataRequest) MergeReport {
+	var report MergeReport
+	var added, overlapping int
+
+	for _, table := range registry.Tables() {
+		_, ours := c.records[table.Name()]
+		_, theirs := other.records[table.Name()]
+		if !ours && !theirs {
+			continue
+		}
+
+		var merged registry.Records
+		merged, added, overlapping = table.Merge(c.records[table.Name()], other.records[table.Name()])
+		c.setRecords(table.Name(), merged)
+		report.add(table.Name(), added, overlapping)
+	}
+
+	tables := c.Unrecognized
+	var appended []*helpers.GenericTable
+	for _, incoming := range other.Unrecognized {
+		i := slices.IndexFunc(tables, func(t *helpers.GenericTable) bool {
+			return t.H1 == incoming.H1 && t.H2 == incoming.H2 && slices.Equal(t.Header, incoming.Header)
+		})
+		if i < 0 {
+			tables = append(tables, incoming)
+			appended = append(appended, incoming)
+			continue
+		}
+
+		added, overlapping = tables[i].MergeRows(incoming.Rows)
+		report.add(tables[i].Name, added, overlapping)
+	}
+
+	// appended tables may be renamed to keep output names unique
+	c.setUnrecognized(tables)
+	for _, table := range appended {
+		report.add(table.Name, len(table.Rows), 0)
+	}
+
+	return report
+}
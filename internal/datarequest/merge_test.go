@@ -0,0 +1,90 @@
+package datarequest
+
+import (
+	// std
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+)
+
+// Returns the export without the rows of the given day, in every table written
+// one row per line
+func exportWithout(data, day string) string {
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if !strings.HasPrefix(line, "<tr><td>"+day+" ") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Two exports overlapping on all but their first and last days merge into the
+// records of the whole export, each overlapping row kept once
+func TestMerge(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "export.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parse := func(export string) *CodDataRequest {
+		t.Helper()
+		c := NewCodDataRequest()
+		if _, err := c.ParseReader(strings.NewReader(export), ParseOptions{Lenient: true, Unrecognized: true, Budget: helpers.ErrorBudget{MaxRows: -1}}); err != nil {
+			t.Fatal(err)
+		}
+		return &c
+	}
+
+	whole := parse(string(data))
+	older := parse(exportWithout(string(data), "2024-05-05"))
+	newer := parse(exportWithout(string(data), "2024-01-01"))
+
+	report := older.Merge(newer)
+	if len(report.Tables) == 0 {
+		t.Fatal("merged no tables")
+	}
+	for _, status := range report.Tables {
+		t.Run(status.Table, func(t *testing.T) {
+			if want := lenOf(newer, status.Table); status.New+status.Overlapping != want {
+				t.Errorf("status %+v, want %d rows in all", status, want)
+			}
+			if got, want := lenOf(older, status.Table), lenOf(whole, status.Table); got != want {
+				t.Errorf("merged %d rows, want %d", got, want)
+			}
+		})
+	}
+	for name, records := range whole.records {
+		if records.Len() == 0 && lenOf(older, name) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(older.records[name], records) {
+			t.Errorf("%s merged records differ from the whole export's", name)
+		}
+	}
+	if !reflect.DeepEqual(older.Unrecognized, whole.Unrecognized) {
+		t.Errorf("merged unrecognized %+v\nwant %+v", older.Unrecognized, whole.Unrecognized)
+	}
+
+	// merging the same export again adds nothing
+	if added, _ := older.Merge(newer).Totals(); added != 0 {
+		t.Errorf("merging again added %d rows, want 0", added)
+	}
+}
+
+// Returns the number of rows of the table, known or unrecognized, by name
+func lenOf(c *CodDataRequest, table string) int {
+	if records := c.Records(table); records != nil {
+		return records.Len()
+	}
+	for _, generic := range c.Unrecognized {
+		if generic.Name == table {
+			return len(generic.Rows)
+		}
+	}
+	return 0
+}
@@ -1,11 +1,7 @@
 package modernwarfaremultiplayer
 
 import (
-	"path"
-	"strconv"
-	"time"
-
-	"github.com/PuerkitoBio/goquery"
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/registry"
 	"github.com/hoodnoah/cod_data_request/internal/helpers"
 )
 
@@ -14,6 +10,13 @@ const (
 	h2Text = "Multiplayer Match Data (reverse chronological)"
 )
 
+// Identifies the table in reports
+const Name = "modernwarfaremultiplayer"
+
+// Finds the table in an export
+var Locator = helpers.NewLocator(h1Text, h2Text).
+	WithH2Patterns(helpers.QualifiedTitlePattern("Multiplayer Match Data"))
+
 var fieldParsers = map[string]helpers.FieldParser{
 	"UTC Timestamp":         helpers.TimestampToUnixMillisInt64(),
 	"Match ID":              helpers.StringParser(),
@@ -30,70 +33,37 @@ var fieldParsers = map[string]helpers.FieldParser{
 	"Total XP Earned":       helpers.IntParser(),
 }
 
-var headerLabels = []string{
-	"UTC Timestamp",
-	"Match ID",
-	"Platform",
-	"Game Type Screen Name",
-	"Map Screen Name",
-	"Rank",
-	"Score",
-	"Assists",
-	"Kills",
-	"Deaths",
-	"Headshots",
-	"Longest Streak",
-	"Total XP Earned",
-}
-
 type MWMultiplayerMatch struct {
 	Timestamp          int64  `col:"UTC Timestamp" parquet:"name=timestamp_utc, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
 	MatchID            string `col:"Match ID" parquet:"name=match_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	Platform           string `col:"Platform" parquet:"name=platform, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	GameTypeScreenName string `col:"Game Type Screen Name" parquet:"name=game_type_screen_name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	MapScreenName      string `col:"Map Screen Name" parquet:"name=map_screen_name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	Rank               int64  `col:"Rank" parquet:"name=rank, type=INT64"`
-	Score              int64  `col:"Score" parquet:"name=score, type=INT64"`
-	Assists            int64  `col:"Assists" parquet:"name=assists, type=INT64"`
-	Kills              int64  `col:"Kills" parquet:"name=kills, type=INT64"`
-	Deaths             int64  `col:"Deaths" parquet:"name=deaths, type=INT64"`
-	Headshots          int64  `col:"Headshots" parquet:"name=headshots, type=INT64"`
-	LongestStreak      int64  `col:"Longest Streak" parquet:"name=longest_streak, type=INT64"`
-	TotalXPEarned      int64  `col:"Total XP Earned" parquet:"name=total_xp_earned, type=INT64"`
+	Rank               *int64 `col:"Rank" parquet:"name=rank, type=INT64, repetitiontype=OPTIONAL"`
+	Score              *int64 `col:"Score" parquet:"name=score, type=INT64, repetitiontype=OPTIONAL"`
+	Assists            *int64 `col:"Assists" parquet:"name=assists, type=INT64, repetitiontype=OPTIONAL"`
+	Kills              *int64 `col:"Kills" parquet:"name=kills, type=INT64, repetitiontype=OPTIONAL"`
+	Deaths             *int64 `col:"Deaths" parquet:"name=deaths, type=INT64, repetitiontype=OPTIONAL"`
+	Headshots          *int64 `col:"Headshots" parquet:"name=headshots, type=INT64, repetitiontype=OPTIONAL"`
+	LongestStreak      *int64 `col:"Longest Streak" parquet:"name=longest_streak, type=INT64, repetitiontype=OPTIONAL"`
+	TotalXPEarned      *int64 `col:"Total XP Earned" parquet:"name=total_xp_earned, type=INT64, repetitiontype=OPTIONAL"`
 }
 
 type MWMultiplayerMatches []*MWMultiplayerMatch
 
-func (m *MWMultiplayerMatch) ToStringSlice() []string {
-	return []string{
-		time.UnixMilli(m.Timestamp).UTC().Format(time.RFC3339),
-		m.MatchID,
-		m.Platform,
-		m.GameTypeScreenName,
-		m.MapScreenName,
-		strconv.FormatInt(m.Rank, 10),
-		strconv.FormatInt(m.Score, 10),
-		strconv.FormatInt(m.Assists, 10),
-		strconv.FormatInt(m.Kills, 10),
-		strconv.FormatInt(m.Deaths, 10),
-		strconv.FormatInt(m.Headshots, 10),
-		strconv.FormatInt(m.LongestStreak, 10),
-		strconv.FormatInt(m.TotalXPEarned, 10),
-	}
+// Identifies the match across overlapping exports
+func (m *MWMultiplayerMatch) Key() string {
+	return helpers.NaturalKey(m.MatchID, m.Timestamp)
 }
 
-var fromRow = helpers.MakeFromRow[MWMultiplayerMatch]("col", fieldParsers)
+var binding = helpers.NewBinding[MWMultiplayerMatch](helpers.ColumnTag, fieldParsers)
 
-func FromHtml(doc *goquery.Document) (MWMultiplayerMatches, error) {
-	return helpers.FromHtmlTable(doc, h1Text, h2Text, fromRow)
-}
-
-func ToCSV(outputDir string, matches *MWMultiplayerMatches) error {
-	filename := path.Join(outputDir, "modern_warfare_multiplayer_matches.csv")
-	return helpers.ToCSV(filename, headerLabels, *matches)
-}
+const outputName = "modern_warfare_multiplayer_matches"
 
-func ToParquet(outputdir string, matches *MWMultiplayerMatches) error {
-	filename := path.Join(outputdir, "modern_warfare_multiplayer_matches.parquet")
-	return helpers.ToParquet(filename, *matches, new(MWMultiplayerMatch))
-}
+// Parses and writes out the table
+var Table = registry.Register(registry.NewTable(registry.Definition[MWMultiplayerMatch]{
+	Name:       Name,
+	Locator:    Locator,
+	Binding:    binding,
+	OutputName: outputName,
+}))
@@ -0,0 +1,33 @@
+package datarequest
+
+import (
+	// std
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// Runs every task, with at most limit running at once, or one per CPU if limit is
+// not positive. The tasks' errors are joined in task order, so the result does not
+// depend on scheduling.
+func runBounded(limit int, tasks []func() error) error {
+	if limit <= 0 {
+		limit = runtime.GOMAXPROCS(0)
+	}
+
+	errs := make([]error, len(tasks))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = task()
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
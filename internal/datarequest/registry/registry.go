@@ -0,0 +1,102 @@
+package registry
+
+import (
+	// std
+	"fmt"
+	"slices"
+	"sync"
+
+	// external
+	"github.com/PuerkitoBio/goquery"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+)
+
+// A table of an export which a package knows how to find, parse and write out.
+// Table packages register one with Register, typically from a package variable,
+// and CodDataRequest handles every registered table in registration order.
+type Table interface {
+	// identifies the table in reports and heading overrides; unique across tables
+	Name() string
+
+	// the headings the table is found under
+	Locator() *helpers.Locator
+
+	// the CSV header, and a pointer to the struct describing the parquet schema
+	Header() []string
+	Schema() any
+
+	// parses the table from a document, or creates a collector gathering it while streaming
+	Parse(doc *goquery.Document, opts helpers.TableOptions) (Records, helpers.TableReport, error)
+	NewCollector(opts helpers.TableOptions) Collector
+
+	// appends the records of src whose key is not already in dst
+	Merge(dst, src Records) (merged Records, added, overlapping int)
+
+	// writes the records to, or plans appending them to, the table's file in the output directory
+	ToCSV(outputDir string, records Records) error
+	ToParquet(outputDir string, records Records) error
+	PlanCSVAppend(outputDir string, records Records) (*helpers.AppendPlan, error)
+	PlanParquetAppend(outputDir string, records Records) (*helpers.AppendPlan, error)
+}
+
+// The records parsed for one table; the table which produced them reads them back
+type Records interface {
+	Len() int
+}
+
+// Gathers one table's records while an export is streamed
+type Collector interface {
+	helpers.TableSink
+	Result() (Records, helpers.TableReport, error)
+}
+
+var (
+	mu     sync.Mutex
+	tables []Table
+)
+
+// Adds a table to the registry, returning it. Tables are parsed, reported and
+// written in the order they are registered; as packages register themselves while
+// initialized, that is the order of their import paths.
+// Panics if a table of the same name is already registered.
+func Register(t Table) Table {
+	if err := Add(t); err != nil {
+		panic("registry: " + err.Error())
+	}
+	return t
+}
+
+// Adds a table to the registry, as Register does, failing rather than panicking
+// if a table of the same name is already registered. Used for tables defined at
+// runtime, e.g. from spec files.
+func Add(t Table) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if slices.ContainsFunc(tables, func(registered Table) bool { return registered.Name() == t.Name() }) {
+		return fmt.Errorf("table %q registered twice", t.Name())
+	}
+	tables = append(tables, t)
+	return nil
+}
+
+// Returns every registered table, in registration order
+func Tables() []Table {
+	mu.Lock()
+	defer mu.Unlock()
+	return slices.Clone(tables)
+}
+
+// Returns the registered table with the given name
+func Lookup(name string) (Table, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	i := slices.IndexFunc(tables, func(t Table) bool { return t.Name() == name })
+	if i < 0 {
+		return nil, false
+	}
+	return tables[i], true
+}
@@ -0,0 +1,131 @@
+package registry
+
+import (
+	// std
+	"path"
+
+	// external
+	"github.com/PuerkitoBio/goquery"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+)
+
+// Describes a table whose rows are bound to the struct T. The binding also
+// derives the table's CSV header and cells from T's parquet tags. A table package
+// passes its definition to NewTable and Register in a package-level variable, so
+// the table is registered as soon as the package is imported.
+type Definition[T any] struct {
+	Name    string
+	Locator *helpers.Locator
+	Binding *helpers.Binding[T]
+
+	// the name of the table's output files, without extension
+	OutputName string
+}
+
+// The methods a table's record type provides on its pointer
+type Record[T any] interface {
+	*T
+	helpers.Keyed
+}
+
+// Creates a Table from its definition
+func NewTable[T any, P Record[T]](def Definition[T]) Table {
+	return &structTable[T, P]{def: def}
+}
+
+// Returns the records of a table bound to T, or nil if there are none
+func RecordsOf[T any](records Records) []*T {
+	if records == nil {
+		return nil
+	}
+	return records.(recordSlice[T])
+}
+
+type recordSlice[T any] []*T
+
+func (r recordSlice[T]) Len() int {
+	return len(r)
+}
+
+// A Table whose records are the struct T, parsed through its binding
+type structTable[T any, P Record[T]] struct {
+	def Definition[T]
+}
+
+func (t *structTable[T, P]) Name() string {
+	return t.def.Name
+}
+
+func (t *structTable[T, P]) Locator() *helpers.Locator {
+	return t.def.Locator
+}
+
+func (t *structTable[T, P]) Header() []string {
+	return t.def.Binding.CSV().Header()
+}
+
+func (t *structTable[T, P]) Schema() any {
+	return new(T)
+}
+
+func (t *structTable[T, P]) Parse(doc *goquery.Document, opts helpers.TableOptions) (Records, helpers.TableReport, error) {
+	records, report, err := helpers.FromHtmlTable(doc, t.def.Locator, t.def.Binding, opts)
+	return recordSlice[T](records), report, err
+}
+
+func (t *structTable[T, P]) NewCollector(opts helpers.TableOptions) Collector {
+	return collector[T]{helpers.NewTableCollector(t.def.Locator, t.def.Binding, opts)}
+}
+
+func (t *structTable[T, P]) Merge(dst, src Records) (Records, int, int) {
+	merged, added, overlapping := helpers.MergeRecords(t.items(dst), t.items(src))
+	records := make(recordSlice[T], len(merged))
+	for i, item := range merged {
+		records[i] = item
+	}
+	return records, added, overlapping
+}
+
+func (t *structTable[T, P]) ToCSV(outputDir string, records Records) error {
+	return helpers.ToCSV(t.fileName(outputDir, ".csv"), t.def.Binding.CSV(), t.items(records))
+}
+
+func (t *structTable[T, P]) ToParquet(outputDir string, records Records) error {
+	return helpers.ToParquet(t.fileName(outputDir, ".parquet"), t.items(records), t.Schema())
+}
+
+// plans appending the records newer than those already in the CSV in the output directory
+func (t *structTable[T, P]) PlanCSVAppend(outputDir string, records Records) (*helpers.AppendPlan, error) {
+	return helpers.PlanCSVAppend(t.fileName(outputDir, ".csv"), t.def.Binding.CSV(), t.items(records))
+}
+
+// plans writing the records newer than those already in the parquet output as a new part file
+func (t *structTable[T, P]) PlanParquetAppend(outputDir string, records Records) (*helpers.AppendPlan, error) {
+	return helpers.PlanParquetAppend(t.fileName(outputDir, ".parquet"), t.items(records), t.Schema())
+}
+
+func (t *structTable[T, P]) fileName(outputDir, ext string) string {
+	return path.Join(outputDir, t.def.OutputName+ext)
+}
+
+// Returns the records as their pointer type, on which the record methods are defined
+func (t *structTable[T, P]) items(records Records) []P {
+	items := RecordsOf[T](records)
+	converted := make([]P, len(items))
+	for i, item := range items {
+		converted[i] = item
+	}
+	return converted
+}
+
+// Gathers the records of a structTable while streaming
+type collector[T any] struct {
+	*helpers.TableCollector[T]
+}
+
+func (c collector[T]) Result() (Records, helpers.TableReport, error) {
+	records, report, err := c.TableCollector.Result()
+	return recordSlice[T](records), report, err
+}
@@ -0,0 +1,127 @@
+package datarequest
+
+import (
+	// std
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+)
+
+// The outcome of parsing a single table
+type TableStatus struct {
+	Table   string              `json:"table"`
+	Source  string              `json:"source,omitempty"`
+	Rows    int                 `json:"rows"`
+	Missing bool                `json:"missing"`
+	Warning string              `json:"warning,omitempty"`
+	Header  []string            `json:"header,omitempty"`
+	Drift   helpers.HeaderDrift `json:"drift"`
+	Rejects []helpers.RowReject `json:"rejects,omitempty"`
+}
+
+// Describes the outcome of parsing an export, table by table
+type ParseReport struct {
+	Tables []TableStatus `json:"tables"`
+}
+
+// Returns the warnings raised while parsing, prefixed by table name and, for merged
+// exports, source
+func (r *ParseReport) Warnings() []string {
+	var warnings []string
+	for _, status := range r.Tables {
+		if status.Warning == "" {
+			continue
+		}
+		if status.Source != "" {
+			warnings = append(warnings, fmt.Sprintf("%s %s: %s", status.Source, status.Table, status.Warning))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", status.Table, status.Warning))
+		}
+	}
+	return warnings
+}
+
+// Appends another report's tables, marking each with the input it was parsed from.
+// Used when several exports are parsed and merged in one run.
+func (r *ParseReport) Append(source string, other *ParseReport) {
+	for _, status := range other.Tables {
+		status.Source = source
+		r.Tables = append(r.Tables, status)
+	}
+}
+
+// Records a table's outcome, returning an error if parsing should stop.
+// A missing section is only tolerated in lenient mode.
+func (r *ParseReport) add(table string, rows int, tableReport helpers.TableReport, err error, opts ParseOptions) error {
+	status := TableStatus{
+		Table:   table,
+		Rows:    rows,
+		Header:  tableReport.Header,
+		Drift:   tableReport.Drift,
+		Rejects: tableReport.Rejects,
+	}
+
+	if err != nil {
+		if !opts.Lenient || !helpers.IsMissingSection(err) {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+		status.Missing = true
+		status.Warning = err.Error()
+	}
+
+	r.Tables = append(r.Tables, status)
+	return nil
+}
+
+// Writes a <table>_rejects.csv file to the output directory for every table which
+// quarantined rows, holding each rejected row's raw cells and parser error. Tables
+// marked with a source are prefixed by its name, e.g. <source>_<table>_rejects.csv.
+func (r *ParseReport) WriteRejects(outputDir string) error {
+	for _, status := range r.Tables {
+		if len(status.Rejects) == 0 {
+			continue
+		}
+
+		name := status.Table
+		if status.Source != "" {
+			name = helpers.ToSnakeCase(strings.TrimSuffix(path.Base(status.Source), path.Ext(status.Source))) + "_" + name
+		}
+
+		filename := path.Join(outputDir, name+"_rejects.csv")
+		if err := helpers.WriteRejects(filename, status.Header, status.Rejects); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes the drift report, comparing each table's observed header with its
+// struct's columns, as JSON to the provided file. Tables of merged exports name
+// the input they were parsed from.
+func (r *ParseReport) WriteDriftReport(fileName string) error {
+	type tableDrift struct {
+		Table  string              `json:"table"`
+		Source string              `json:"source,omitempty"`
+		Header []string            `json:"header"`
+		Drift  helpers.HeaderDrift `json:"drift"`
+	}
+
+	drifts := make([]tableDrift, 0, len(r.Tables))
+	for _, status := range r.Tables {
+		if status.Missing {
+			continue
+		}
+		drifts = append(drifts, tableDrift{Table: status.Table, Source: status.Source, Header: status.Header, Drift: status.Drift})
+	}
+
+	data, err := json.MarshalIndent(drifts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, data, 0o644)
+}
@@ -0,0 +1,152 @@
+package datarequest
+
+import (
+	// std
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	// external
+	"github.com/PuerkitoBio/goquery"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+)
+
+func TestReportAdd(t *testing.T) {
+	notFound := fmt.Errorf("failed to find table: %w", helpers.ErrSectionNotFound)
+	budget := fmt.Errorf("row 3: %w", helpers.ErrBudgetExceeded)
+
+	tests := []struct {
+		name        string
+		err         error
+		lenient     bool
+		wantErr     bool
+		wantMissing bool
+	}{
+		{name: "parsed"},
+		{name: "section not found, strict", err: notFound, wantErr: true},
+		{name: "section not found, lenient", err: notFound, lenient: true, wantMissing: true},
+		{name: "no rows, strict", err: helpers.ErrNoRows, wantErr: true},
+		{name: "no rows, lenient", err: helpers.ErrNoRows, lenient: true, wantMissing: true},
+		{name: "budget exceeded, lenient", err: budget, lenient: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var report ParseReport
+			err := report.add("matches", 0, helpers.TableReport{}, tt.err, ParseOptions{Lenient: tt.lenient})
+			if tt.wantErr {
+				if err == nil || !errors.Is(err, tt.err) || !strings.HasPrefix(err.Error(), "matches: ") {
+					t.Fatalf("error %v, want %v prefixed by the table", err, tt.err)
+				}
+				if len(report.Tables) != 0 {
+					t.Errorf("report %+v, want no tables after an error", report.Tables)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Tables) != 1 {
+				t.Fatalf("report %+v, want one table", report.Tables)
+			}
+			status := report.Tables[0]
+			if status.Missing != tt.wantMissing || (status.Warning != "") != tt.wantMissing {
+				t.Errorf("status %+v, want missing %v with a warning", status, tt.wantMissing)
+			}
+		})
+	}
+}
+
+// The export's Black Ops 6 campaign heading is followed by no table: a lenient
+// parse reports it missing and parses the rest, while a strict parse fails,
+// whichever reader is used
+func TestParseMissingSection(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "export.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsers := map[string]func(c *CodDataRequest, opts ParseOptions) (*ParseReport, error){
+		"ParseHtml": func(c *CodDataRequest, opts ParseOptions) (*ParseReport, error) {
+			doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return c.ParseHtml(doc, opts)
+		},
+		"ParseReader": func(c *CodDataRequest, opts ParseOptions) (*ParseReport, error) {
+			return c.ParseReader(bytes.NewReader(data), opts)
+		},
+	}
+
+	for name, parse := range parsers {
+		t.Run(name, func(t *testing.T) {
+			budget := helpers.ErrorBudget{MaxRows: -1}
+
+			strict := NewCodDataRequest()
+			if _, err := parse(&strict, ParseOptions{Budget: budget}); !errors.Is(err, helpers.ErrSectionNotFound) || !strings.Contains(err.Error(), "blops6campaign: ") {
+				t.Errorf("strict error %v, want blops6campaign not found", err)
+			}
+
+			lenient := NewCodDataRequest()
+			report, err := parse(&lenient, ParseOptions{Lenient: true, Budget: budget})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, status := range report.Tables {
+				missing := status.Table == "blops6campaign"
+				if status.Missing != missing {
+					t.Errorf("%s missing %v, want %v", status.Table, status.Missing, missing)
+				}
+				if missing && (status.Warning == "" || status.Rows != 0) {
+					t.Errorf("%s status %+v, want a warning and no rows", status.Table, status)
+				}
+				if !missing && status.Rows == 0 {
+					t.Errorf("%s parsed no rows", status.Table)
+				}
+			}
+			if warnings := report.Warnings(); len(warnings) != 1 || !strings.HasPrefix(warnings[0], "blops6campaign: ") {
+				t.Errorf("warnings %q, want one for blops6campaign", warnings)
+			}
+		})
+	}
+}
+
+// Each drift entry of merged exports names its input; missing tables are left out
+func TestWriteDriftReport(t *testing.T) {
+	first := &ParseReport{Tables: []TableStatus{
+		{Table: "warzone2", Header: []string{"A"}, Drift: helpers.HeaderDrift{Unknown: []string{"A"}}},
+		{Table: "coldwarzombies", Missing: true},
+	}}
+	second := &ParseReport{Tables: []TableStatus{{Table: "warzone2", Header: []string{"B"}}}}
+
+	var merged ParseReport
+	merged.Append("old.zip", first)
+	merged.Append("new.zip", second)
+
+	fileName := filepath.Join(t.TempDir(), "drift.json")
+	if err := merged.WriteDriftReport(fileName); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []struct {
+		Table  string   `json:"table"`
+		Source string   `json:"source"`
+		Header []string `json:"header"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Source != "old.zip" || entries[0].Header[0] != "A" || entries[1].Source != "new.zip" || entries[1].Header[0] != "B" {
+		t.Errorf("drift report %s, want warzone2 from old.zip then new.zip", data)
+	}
+}
@@ -0,0 +1,154 @@
+package spec
+
+import (
+	// std
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// external
+	"gopkg.in/yaml.v3"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/registry"
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+)
+
+// The column types a spec may declare, and the values they are parsed into
+const (
+	TypeString    = "string"    // the cell's text
+	TypeInt       = "int"       // a whole number, as IntParser reads it
+	TypeFloat     = "float"     // a number, as FloatParser reads it
+	TypeTimestamp = "timestamp" // a point in time, stored as unix milliseconds
+	TypeDuration  = "duration"  // a length of time, as DurationParser reads it
+)
+
+// A file of table specs, e.g.
+//
+//	tables:
+//	  - name: blops6zombies
+//	    output: black_ops_6_zombies_matches
+//	    h1: "Call of Duty: Black Ops 6"
+//	    h2: "Zombies Match Data (reverse chronological)"
+//	    key: [timestamp_utc, map]
+//	    timestamp: timestamp_utc
+//	    columns:
+//	      - {source: UTC Timestamp, name: timestamp_utc, type: timestamp}
+//	      - {source: Map, name: map, type: string}
+//	      - {source: Kills, name: kills, type: int, nullable: true}
+//	      - {source: Time Played, name: time_played_ns, type: duration, nullable: true}
+type File struct {
+	Tables []Spec `yaml:"tables" json:"tables"`
+}
+
+// Describes a table to be read from an export without a Go package of its own
+type Spec struct {
+	// identifies the table in reports and heading overrides
+	Name string `yaml:"name" json:"name"`
+
+	// the name of the table's output files, without extension; defaults to Name
+	Output string `yaml:"output" json:"output"`
+
+	// the headings the table is found under, and any further titles or patterns
+	H1       string                  `yaml:"h1" json:"h1"`
+	H2       string                  `yaml:"h2" json:"h2"`
+	Headings helpers.LocatorOverride `yaml:"headings" json:"headings"`
+
+	// the output columns identifying a record across overlapping exports; every
+	// column if empty
+	Key []string `yaml:"key" json:"key"`
+
+	// the output column appends find the rows already written by: a timestamp
+	// column, not nullable. Defaults to such a column read from "UTC Timestamp", if
+	// any; a table with neither cannot be appended to.
+	Timestamp string `yaml:"timestamp" json:"timestamp"`
+
+	Columns []ColumnSpec `yaml:"columns" json:"columns"`
+}
+
+// Describes one column: the header cell it is read from, and how it is parsed and written
+type ColumnSpec struct {
+	// the column's header cell in the export
+	Source string `yaml:"source" json:"source"`
+
+	// the column's name in CSV and parquet output. A duration column is written
+	// to CSV in seconds and in ISO-8601, as <name>_s and <name>_iso, without any
+	// _ns suffix of name; e.g. time_played_s and time_played_iso for time_played_ns.
+	Name string `yaml:"name" json:"name"`
+
+	// one of the Type constants
+	Type string `yaml:"type" json:"type"`
+
+	// whether null cells are kept as null, in an OPTIONAL parquet column. Otherwise
+	// a null cell rejects its row, except in a string column, which keeps its text.
+	Nullable bool `yaml:"nullable" json:"nullable"`
+
+	Parser ParserSpec `yaml:"parser" json:"parser"`
+}
+
+// Options for a column's parser; each applies only to the types named
+type ParserSpec struct {
+	// timestamp: the layouts tried, in place of helpers.DefaultTimeLayouts
+	Layouts []string `yaml:"layouts" json:"layouts"`
+
+	// timestamp: the IANA zone of timestamps without an offset, rather than UTC
+	Location string `yaml:"location" json:"location"`
+
+	// int, float: "." or ","; inferred from each cell if empty
+	DecimalMark string `yaml:"decimal_mark" json:"decimal_mark"`
+
+	// float: the decimals kept, and how they are rounded: "half_away_from_zero"
+	// (the default), "half_even" or "truncate"
+	Precision *int   `yaml:"precision" json:"precision"`
+	Rounding  string `yaml:"rounding" json:"rounding"`
+}
+
+// Reads a file of table specs, as JSON if named *.json, or YAML otherwise.
+// Unknown fields are rejected, to catch misspelt options.
+func Load(fileName string) ([]Spec, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	if strings.EqualFold(filepath.Ext(fileName), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&file)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse table specs %s: %w", fileName, err)
+	}
+	return file.Tables, nil
+}
+
+// Reads a file of table specs and registers a table for each, after the tables
+// built in. Fails, registering none, if any spec is invalid; a spec named after a
+// table already registered fails when it is reached.
+func Register(fileName string) error {
+	specs, err := Load(fileName)
+	if err != nil {
+		return err
+	}
+
+	tables := make([]registry.Table, len(specs))
+	for i, spec := range specs {
+		if tables[i], err = NewTable(spec); err != nil {
+			return fmt.Errorf("%s: %w", fileName, err)
+		}
+	}
+	for _, table := range tables {
+		if err := registry.Add(table); err != nil {
+			return fmt.Errorf("%s: %w", fileName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package spec
+
+import (
+	// std
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	// internal
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/registry"
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+)
+
+// A column of a spec table, as laid out in its struct
+type column struct {
+	spec  ColumnSpec
+	field int
+}
+
+// A table built from a spec. Its records are structs built at runtime, tagged as a
+// table package's struct would be, so they parse, merge and write out the same way.
+type table struct {
+	registry.Table // parses, merges and writes CSV through Record's struct
+
+	typ    reflect.Type
+	output string
+
+	// whether a column is marked as the one appends are ordered by
+	appendable bool
+}
+
+// Builds a table from its spec, failing if the spec is incomplete or inconsistent
+func NewTable(spec Spec) (registry.Table, error) {
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("table %q: %w", spec.Name, err)
+	}
+
+	loc := helpers.NewLocator(spec.H1, spec.H2)
+	if err := loc.Extend(spec.Headings); err != nil {
+		return nil, fmt.Errorf("table %q: headings: %w", spec.Name, err)
+	}
+
+	timestamp := spec.timestampColumn()
+
+	fields := make([]reflect.StructField, len(spec.Columns))
+	parsers := make(map[string]helpers.FieldParser, len(spec.Columns))
+	columns := make([]column, len(spec.Columns))
+	for i, col := range spec.Columns {
+		parser, err := col.parser()
+		if err != nil {
+			return nil, fmt.Errorf("table %q: column %q: %w", spec.Name, col.Name, err)
+		}
+		parsers[col.Source] = parser
+		fields[i] = col.structField(i, col.Name == timestamp)
+		columns[i] = column{spec: col, field: i}
+	}
+
+	key := columns
+	if len(spec.Key) > 0 {
+		key = nil
+		for _, name := range spec.Key {
+			i := slices.IndexFunc(columns, func(c column) bool { return c.spec.Name == name })
+			key = append(key, columns[i])
+		}
+	}
+
+	typ := reflect.StructOf(fields)
+	binding := helpers.NewDynamicBinding(typ, helpers.ColumnTag, parsers, func(ptr reflect.Value) *Record {
+		return &Record{key: key, value: ptr}
+	})
+
+	output := spec.Output
+	if output == "" {
+		output = spec.Name
+	}
+
+	return &table{
+		Table: registry.NewTable(registry.Definition[Record]{
+			Name:       spec.Name,
+			Locator:    loc,
+			Binding:    binding,
+			OutputName: output,
+		}),
+		typ:        typ,
+		output:     output,
+		appendable: timestamp != "",
+	}, nil
+}
+
+// The output column names accepted, which need no quoting in CSV or parquet
+var columnName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (s Spec) validate() error {
+	switch {
+	case s.Name == "":
+		return errors.New("no name")
+	case s.H1 == "" || s.H2 == "":
+		return errors.New("h1 and h2 are required")
+	case len(s.Columns) == 0:
+		return errors.New("no columns")
+	}
+
+	names := make(map[string]bool)
+	sources := make(map[string]bool)
+	for _, col := range s.Columns {
+		switch {
+		case col.Source == "" || col.Name == "":
+			return errors.New("every column needs a source and a name")
+		case !columnName.MatchString(col.Name):
+			return fmt.Errorf("column name %q must be letters, digits and underscores", col.Name)
+		case sources[col.Source]:
+			return fmt.Errorf("source column %q is listed twice", col.Source)
+		}
+		sources[col.Source] = true
+
+		outputs := []string{col.Name}
+		if col.Type == TypeDuration {
+			base := strings.TrimSuffix(col.Name, "_ns")
+			outputs = append(outputs, base+"_s", base+"_iso")
+		}
+		for _, name := range outputs {
+			if names[name] {
+				return fmt.Errorf("output column %q is listed twice", name)
+			}
+			names[name] = true
+		}
+	}
+
+	for _, name := range s.Key {
+		if !slices.ContainsFunc(s.Columns, func(col ColumnSpec) bool { return col.Name == name }) {
+			return fmt.Errorf("key column %q is not a column", name)
+		}
+	}
+
+	if s.Timestamp != "" {
+		i := slices.IndexFunc(s.Columns, func(col ColumnSpec) bool { return col.Name == s.Timestamp })
+		switch {
+		case i < 0:
+			return fmt.Errorf("timestamp column %q is not a column", s.Timestamp)
+		case s.Columns[i].Type != TypeTimestamp || s.Columns[i].Nullable:
+			return fmt.Errorf("timestamp column %q must be a timestamp, not nullable", s.Timestamp)
+		}
+	}
+	return nil
+}
+
+// Returns the name of the column appends are ordered by, or "" if there is none
+func (s Spec) timestampColumn() string {
+	if s.Timestamp != "" {
+		return s.Timestamp
+	}
+	for _, col := range s.Columns {
+		if col.Source == helpers.TimestampColumn && col.Type == TypeTimestamp && !col.Nullable {
+			return col.Name
+		}
+	}
+	return ""
+}
+
+// Returns the column's parser, configured by its parser options
+func (c ColumnSpec) parser() (helpers.FieldParser, error) {
+	switch c.Type {
+	case TypeString:
+		if !c.Nullable {
+			return helpers.StringParser(), nil
+		}
+		return func(s string) (any, error) {
+			if helpers.IsNull(s) {
+				return nil, nil
+			}
+			return s, nil
+		}, nil
+	case TypeInt, TypeFloat:
+		opts, err := c.Parser.numberOptions(c.Type)
+		if err != nil {
+			return nil, err
+		}
+		if c.Type == TypeInt {
+			return helpers.IntParser(opts...), nil
+		}
+		return helpers.FloatParser(opts...), nil
+	case TypeTimestamp:
+		opts, err := c.Parser.timeOptions()
+		if err != nil {
+			return nil, err
+		}
+		return helpers.TimestampToUnixMillisInt64(opts...), nil
+	case TypeDuration:
+		return helpers.DurationParser(), nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", c.Type)
+	}
+}
+
+func (p ParserSpec) numberOptions(typ string) ([]helpers.NumberOption, error) {
+	var opts []helpers.NumberOption
+	switch p.DecimalMark {
+	case "":
+	case ".", ",":
+		opts = append(opts, helpers.WithDecimalMark(rune(p.DecimalMark[0])))
+	default:
+		return nil, fmt.Errorf("decimal mark %q is not \".\" or \",\"", p.DecimalMark)
+	}
+
+	if p.Precision == nil {
+		if p.Rounding != "" {
+			return nil, errors.New("rounding needs a precision")
+		}
+		return opts, nil
+	}
+	if typ != TypeFloat {
+		return nil, errors.New("precision only applies to floats")
+	}
+	if *p.Precision < 0 {
+		return nil, fmt.Errorf("negative precision %d", *p.Precision)
+	}
+
+	var rounding helpers.Rounding
+	switch p.Rounding {
+	case "", "half_away_from_zero":
+		rounding = helpers.RoundHalfAwayFromZero
+	case "half_even":
+		rounding = helpers.RoundHalfEven
+	case "truncate":
+		rounding = helpers.RoundTruncate
+	default:
+		return nil, fmt.Errorf("unknown rounding %q", p.Rounding)
+	}
+	return append(opts, helpers.WithPrecision(*p.Precision, rounding)), nil
+}
+
+func (p ParserSpec) timeOptions() ([]helpers.TimeOption, error) {
+	var opts []helpers.TimeOption
+	if len(p.Layouts) > 0 {
+		opts = append(opts, helpers.WithLayouts(p.Layouts...))
+	}
+	if p.Location != "" {
+		loc, err := time.LoadLocation(p.Location)
+		if err != nil {
+			return nil, fmt.Errorf("location: %w", err)
+		}
+		opts = append(opts, helpers.WithLocation(loc))
+	}
+	return opts, nil
+}
+
+// Returns the struct field holding the column, tagged with its source column and
+// parquet schema, and if since is set, as the field appends are ordered by;
+// nullable columns are pointers, in OPTIONAL parquet columns.
+func (c ColumnSpec) structField(i int, since bool) reflect.StructField {
+	var (
+		typ    reflect.Type
+		schema string
+	)
+	switch c.Type {
+	case TypeString:
+		typ, schema = reflect.TypeFor[string](), "type=BYTE_ARRAY, convertedtype=UTF8"
+	case TypeInt:
+		typ, schema = reflect.TypeFor[int64](), "type=INT64"
+	case TypeFloat:
+		typ, schema = reflect.TypeFor[float64](), "type=DOUBLE"
+	case TypeTimestamp:
+		typ, schema = reflect.TypeFor[int64](), "type=INT64, convertedtype=TIMESTAMP_MILLIS"
+	case TypeDuration:
+		typ, schema = reflect.TypeFor[time.Duration](), "type=INT64"
+	}
+
+	repetition := "REQUIRED"
+	if c.Nullable {
+		typ, repetition = reflect.PointerTo(typ), "OPTIONAL"
+	}
+
+	tag := fmt.Sprintf(`%s:%q parquet:"name=%s, %s, repetitiontype=%s"`, helpers.ColumnTag, c.Source, c.Name, schema, repetition)
+	if since {
+		tag += fmt.Sprintf(` %s:"since"`, helpers.AppendTag)
+	}
+	return reflect.StructField{
+		Name: fmt.Sprintf("Column%d", i),
+		Type: typ,
+		Tag:  reflect.StructTag(tag),
+	}
+}
+
+// Returns a pointer to a zero value of the table's struct
+func (t *table) Schema() any {
+	return reflect.New(t.typ).Interface()
+}
+
+func (t *table) ToParquet(outputDir string, records registry.Records) error {
+	return helpers.ToParquet(path.Join(outputDir, t.output+".parquet"), structs(records), t.Schema())
+}
+
+// plans appending the records newer than those already in the CSV output
+func (t *table) PlanCSVAppend(outputDir string, records registry.Records) (*helpers.AppendPlan, error) {
+	if err := t.checkAppendable(); err != nil {
+		return nil, err
+	}
+	return t.Table.PlanCSVAppend(outputDir, records)
+}
+
+// plans writing the records newer than those already in the parquet output as a new part file
+func (t *table) PlanParquetAppend(outputDir string, records registry.Records) (*helpers.AppendPlan, error) {
+	if err := t.checkAppendable(); err != nil {
+		return nil, err
+	}
+	return helpers.PlanParquetAppend(path.Join(outputDir, t.output+".parquet"), structs(records), t.Schema())
+}
+
+// Fails unless the spec declares the timestamp column appends are ordered by
+func (t *table) checkAppendable() error {
+	if !t.appendable {
+		return fmt.Errorf("table %q cannot be appended to: its spec declares no timestamp column", t.Name())
+	}
+	return nil
+}
+
+// Returns the structs the records wrap, which parquet is written from
+func structs(records registry.Records) []any {
+	items := registry.RecordsOf[Record](records)
+	values := make([]any, len(items))
+	for i, item := range items {
+		values[i] = item.Struct()
+	}
+	return values
+}
+
+// A row of a table built from a spec
+type Record struct {
+	key   []column
+	value reflect.Value // pointer to the table's struct
+}
+
+// Returns a pointer to the struct holding the record's values
+func (r *Record) Struct() any {
+	return r.value.Interface()
+}
+
+func (r *Record) field(c column) any {
+	v := r.value.Elem().Field(c.field)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// Identifies the record across overlapping exports by its key columns
+func (r *Record) Key() string {
+	values := make([]any, len(r.key))
+	for i, c := range r.key {
+		values[i] = r.field(c)
+	}
+	return helpers.NaturalKey(values...)
+}
@@ -0,0 +1,150 @@
+package spec
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/hoodnoah/cod_data_request/internal/datarequest/registry"
+	"github.com/hoodnoah/cod_data_request/internal/helpers"
+)
+
+// A spec whose timestamp is its second column, and not read from "UTC Timestamp"
+func otherGame(timestamp string) Spec {
+	return Spec{
+		Name:      "othergame",
+		H1:        "Other Game",
+		H2:        "Stuff",
+		Timestamp: timestamp,
+		Columns: []ColumnSpec{
+			{Source: "A", Name: "a", Type: TypeInt, Nullable: true},
+			{Source: "B", Name: "b", Type: TypeTimestamp},
+		},
+	}
+}
+
+// Parses rows of "a,b" cells under the spec's headings and source columns
+func parse(t *testing.T, spec Spec, tbl registry.Table, rows ...string) registry.Records {
+	t.Helper()
+	html := `<h1>Other Game</h1><h2>Stuff</h2><table><tr>`
+	for _, col := range spec.Columns {
+		html += "<th>" + col.Source + "</th>"
+	}
+	html += "</tr>"
+	for _, row := range rows {
+		a, b, _ := strings.Cut(row, ",")
+		html += "<tr><td>" + a + "</td><td>" + b + "</td></tr>"
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html + "</table>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, _, err := tbl.Parse(doc, helpers.TableOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestTimestampValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		timestamp string
+		nullable  bool
+		wantErr   string
+	}{
+		{"timestamp column", "b", false, ""},
+		{"no timestamp", "", false, ""},
+		{"unknown column", "c", false, `timestamp column "c" is not a column`},
+		{"not a timestamp", "a", false, `timestamp column "a" must be a timestamp`},
+		{"nullable", "b", true, `timestamp column "b" must be a timestamp`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := otherGame(tt.timestamp)
+			spec.Columns[1].Nullable = tt.nullable
+
+			_, err := NewTable(spec)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("NewTable returned %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("NewTable returned %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppendWithoutTimestamp(t *testing.T) {
+	spec := otherGame("")
+	tbl, err := NewTable(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := parse(t, spec, tbl, "1,2024-01-01 00:00:00")
+
+	dir := t.TempDir()
+	if _, err := tbl.PlanCSVAppend(dir, records); err == nil || !strings.Contains(err.Error(), "declares no timestamp column") {
+		t.Errorf("CSV append returned %v, want a refusal", err)
+	}
+	if _, err := tbl.PlanParquetAppend(dir, records); err == nil || !strings.Contains(err.Error(), "declares no timestamp column") {
+		t.Errorf("parquet append returned %v, want a refusal", err)
+	}
+}
+
+func TestAppendByTimestampColumn(t *testing.T) {
+	for _, spec := range []Spec{otherGame("b"), defaultTimestamp()} {
+		t.Run(spec.Columns[1].Source, func(t *testing.T) {
+			tbl, err := NewTable(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+
+			first := parse(t, spec, tbl, "1,2024-01-01 00:00:00", "2,2024-01-02 00:00:00")
+			if err := tbl.ToCSV(dir, first); err != nil {
+				t.Fatal(err)
+			}
+
+			second := parse(t, spec, tbl, "2,2024-01-02 00:00:00", "3,2024-01-03 00:00:00")
+			plan, err := tbl.PlanCSVAppend(dir, second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.Rows != 1 || plan.Skipped != 1 {
+				t.Fatalf("planned %d rows and skipped %d, want 1 and 1", plan.Rows, plan.Skipped)
+			}
+			if err := plan.Write(); err != nil {
+				t.Fatal(err)
+			}
+
+			f, err := os.Open(filepath.Join(dir, "othergame.csv"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			rows, err := csv.NewReader(f).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(rows); got != 4 {
+				t.Fatalf("CSV has %d rows, want a header and 3 records: %q", got, rows)
+			}
+			if got := rows[3]; got[0] != "3" || got[1] != "2024-01-03T00:00:00Z" {
+				t.Errorf("appended row is %q", got)
+			}
+		})
+	}
+}
+
+// A spec whose timestamp column is read from "UTC Timestamp", undeclared
+func defaultTimestamp() Spec {
+	spec := otherGame("")
+	spec.Columns[1].Source = helpers.TimestampColumn
+	return spec
+}
@@ -0,0 +1,15 @@
+package datarequest
+
+// Each table package registers its table when initialized. Packages are
+// initialized in the order of their import paths, which is the order tables are
+// parsed, reported and written in.
+import (
+	// internal
+	_ "github.com/hoodnoah/cod_data_request/internal/datarequest/blops6campaign"
+	_ "github.com/hoodnoah/cod_data_request/internal/datarequest/blops6multiplayer"
+	_ "github.com/hoodnoah/cod_data_request/internal/datarequest/coldwarzombies"
+	_ "github.com/hoodnoah/cod_data_request/internal/datarequest/modernwarfarecampaign"
+	_ "github.com/hoodnoah/cod_data_request/internal/datarequest/modernwarfarecoop"
+	_ "github.com/hoodnoah/cod_data_request/internal/datarequest/modernwarfaremultiplayer"
+	_ "github.com/hoodnoah/cod_data_request/internal/datarequest/warzone2"
+)
@@ -0,0 +1,385 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hoodnoah/cod_data_request/internal/types"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/schema"
+)
+
+// The column every table is ordered by, used to find the newest row already written
+const TimestampColumn = "UTC Timestamp"
+
+// The struct tag marking, as `append:"since"`, the timestamp field appends find the
+// newest row already written by, for structs not reading it from TimestampColumn
+const AppendTag = "append"
+
+var ErrSchemaMismatch = errors.New("existing output does not match the current schema")
+
+// An append of the records newer than a table's existing output, checked against
+// that output but not yet written
+type AppendPlan struct {
+	// the file the records will be written to
+	FileName string
+
+	// the newest timestamp already written, in unix milliseconds, if any output exists
+	Since    int64
+	Existing bool
+
+	// how many records are newer than Since, and how many are not
+	Rows    int
+	Skipped int
+
+	write func() error
+}
+
+// Writes the planned records; does nothing if none are newer than the existing output
+func (p *AppendPlan) Write() error {
+	if p.Rows == 0 {
+		return nil
+	}
+	return p.write()
+}
+
+// Plans appending the items newer than those in the CSV at fileName, which must
+// have exactly the encoder's header. The file is created if it does not exist.
+func PlanCSVAppend[T any](fileName string, encoder *CSVEncoder, items []T) (*AppendPlan, error) {
+	plan := &AppendPlan{FileName: fileName}
+
+	field, err := sinceField(encoder.typ)
+	if err != nil {
+		return nil, fmt.Errorf("cannot append to %s: %w", fileName, err)
+	}
+	column, ok := encoder.column(field)
+	if !ok {
+		return nil, fmt.Errorf("cannot append to %s: the timestamp field %s is not written to CSV", fileName, encoder.typ.Field(field).Name)
+	}
+
+	since, existing, err := csvHighWaterMark(fileName, encoder.Header(), column)
+	if err != nil {
+		return nil, err
+	}
+	plan.Since, plan.Existing = since, existing
+
+	newer, err := newerThan(plan, items)
+	if err != nil {
+		return nil, err
+	}
+
+	if !existing {
+		plan.write = func() error { return ToCSV(fileName, encoder, newer) }
+		return plan, nil
+	}
+	plan.write = func() error { return appendCSV(fileName, encoder, newer) }
+	return plan, nil
+}
+
+// Plans writing the items newer than those in the parquet file at fileName and its
+// part files, e.g. matches.parquet and matches.part-0001.parquet, as the next part
+// file. Every existing file must have the schema of schemaObj. If none exist, the
+// items are written to fileName itself.
+func PlanParquetAppend[T types.ParquetExportable](fileName string, items []T, schemaObj any) (*AppendPlan, error) {
+	if _, err := sinceField(reflect.Indirect(reflect.ValueOf(schemaObj)).Type()); err != nil {
+		return nil, fmt.Errorf("cannot append to %s: %w", fileName, err)
+	}
+
+	existingFiles, nextPart, err := parquetParts(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &AppendPlan{FileName: fileName}
+	if len(existingFiles) > 0 {
+		plan.FileName = fmt.Sprintf("%s.part-%04d.parquet", strings.TrimSuffix(fileName, ".parquet"), nextPart)
+	}
+
+	for _, existing := range existingFiles {
+		since, found, err := parquetHighWaterMark(existing, schemaObj)
+		if err != nil {
+			return nil, err
+		}
+		if found && (!plan.Existing || since > plan.Since) {
+			plan.Since = since
+		}
+		plan.Existing = plan.Existing || found
+	}
+
+	newer, err := newerThan(plan, items)
+	if err != nil {
+		return nil, err
+	}
+	plan.write = func() error { return ToParquet(plan.FileName, newer, schemaObj) }
+	return plan, nil
+}
+
+// Returns the items newer than the plan's high-water mark, counting them into the plan
+func newerThan[T any](plan *AppendPlan, items []T) ([]T, error) {
+	if !plan.Existing {
+		plan.Rows = len(items)
+		return items, nil
+	}
+
+	var newer []T
+	for _, item := range items {
+		ts, err := recordTimestamp(item)
+		if err != nil {
+			return nil, err
+		}
+		if ts > plan.Since {
+			newer = append(newer, item)
+		}
+	}
+	plan.Rows, plan.Skipped = len(newer), len(items)-len(newer)
+	return newer, nil
+}
+
+// Returns the index of the field appends are ordered by: the field tagged
+// `append:"since"`, or failing one, the field read from TimestampColumn. The field
+// must be a required TIMESTAMP_MILLIS int64, so that every record has a timestamp.
+func sinceField(t reflect.Type) (int, error) {
+	field := -1
+	for i := range t.NumField() {
+		if t.Field(i).Tag.Get(AppendTag) == "since" {
+			field = i
+			break
+		}
+		if field < 0 && t.Field(i).Tag.Get(ColumnTag) == TimestampColumn {
+			field = i
+		}
+	}
+	if field < 0 {
+		return 0, fmt.Errorf("no timestamp field to find the rows already written by: none is tagged %s:\"since\" or read from column %q", AppendTag, TimestampColumn)
+	}
+
+	f := t.Field(field)
+	if f.Type.Kind() != reflect.Int64 || parquetTagValue(f.Tag.Get("parquet"), "convertedtype") != "TIMESTAMP_MILLIS" {
+		return 0, fmt.Errorf("timestamp field %s is not a required TIMESTAMP_MILLIS int64", f.Name)
+	}
+	return field, nil
+}
+
+// Reads the field appends are ordered by from a record, pointer to one, or
+// StructRecord
+func recordTimestamp(record any) (int64, error) {
+	if wrapped, ok := record.(StructRecord); ok {
+		record = wrapped.Struct()
+	}
+	v := reflect.Indirect(reflect.ValueOf(record))
+	field, err := sinceField(v.Type())
+	if err != nil {
+		return 0, err
+	}
+	return v.Field(field).Int(), nil
+}
+
+// Returns the newest timestamp in the named column of the CSV, written in
+// TimestampLayout, or false if the file does not exist or holds no rows. Fails if
+// the file's header differs from the expected one.
+func csvHighWaterMark(fileName string, header []string, column string) (int64, bool, error) {
+	file, err := os.Open(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	existingHeader, err := r.Read()
+	if err == io.EOF {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read %s: %w", fileName, err)
+	}
+	if err := compareHeader(existingHeader, header); err != nil {
+		return 0, false, fmt.Errorf("%s: %w: %v", fileName, ErrSchemaMismatch, err)
+	}
+	col := slices.Index(existingHeader, column)
+	if col < 0 {
+		return 0, false, fmt.Errorf("%s: %w: no %q column", fileName, ErrSchemaMismatch, column)
+	}
+
+	var (
+		since int64
+		found bool
+	)
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to read %s: %w", fileName, err)
+		}
+
+		ts, err := time.Parse(TimestampLayout, row[col])
+		if err != nil {
+			return 0, false, fmt.Errorf("%s: invalid %s %q: %w", fileName, column, row[col], err)
+		}
+		if !found || ts.UnixMilli() > since {
+			since, found = ts.UnixMilli(), true
+		}
+	}
+	return since, found, nil
+}
+
+// Describes the first difference between two CSV headers, if any
+func compareHeader(actual, expected []string) error {
+	for i := range min(len(actual), len(expected)) {
+		if actual[i] != expected[i] {
+			return fmt.Errorf("column %d is %q, expected %q", i+1, actual[i], expected[i])
+		}
+	}
+	if len(actual) != len(expected) {
+		return fmt.Errorf("%d columns, expected %d", len(actual), len(expected))
+	}
+	return nil
+}
+
+// Writes the items to the end of an existing CSV, without a header
+func appendCSV[T any](fileName string, encoder *CSVEncoder, items []T) error {
+	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	for _, item := range items {
+		if err := writer.Write(encoder.Row(item)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Lists the parquet file and its part files which already exist, and the number of
+// the next part file.
+func parquetParts(fileName string) ([]string, int, error) {
+	base := strings.TrimSuffix(fileName, ".parquet")
+
+	var files []string
+	if _, err := os.Stat(fileName); err == nil {
+		files = append(files, fileName)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, 0, err
+	}
+
+	parts, err := filepath.Glob(base + ".part-*.parquet")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	next := 1
+	for _, part := range parts {
+		num := strings.TrimSuffix(strings.TrimPrefix(part, base+".part-"), ".parquet")
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			continue
+		}
+		files = append(files, part)
+		next = max(next, n+1)
+	}
+	return files, next, nil
+}
+
+// Returns the newest timestamp in the parquet file, or false if it holds no rows.
+// Fails if the file's schema differs from that of schemaObj.
+func parquetHighWaterMark(fileName string, schemaObj any) (int64, bool, error) {
+	expected, err := schema.NewSchemaHandlerFromStruct(schemaObj)
+	if err != nil {
+		return 0, false, err
+	}
+
+	pf, err := local.NewLocalFileReader(fileName)
+	if err != nil {
+		return 0, false, err
+	}
+	defer pf.Close()
+
+	pr, err := reader.NewParquetReader(pf, nil, 1)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read %s: %w", fileName, err)
+	}
+	defer pr.ReadStop()
+
+	if err := compareSchema(pr.SchemaHandler, expected); err != nil {
+		return 0, false, fmt.Errorf("%s: %w: %v", fileName, ErrSchemaMismatch, err)
+	}
+
+	col, err := timestampLeaf(schemaObj, expected)
+	if err != nil {
+		return 0, false, err
+	}
+
+	values, _, _, err := pr.ReadColumnByIndex(int64(col), pr.GetNumRows())
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read %s: %w", fileName, err)
+	}
+
+	var (
+		since int64
+		found bool
+	)
+	for _, value := range values {
+		ts, ok := value.(int64)
+		if !ok {
+			continue
+		}
+		if !found || ts > since {
+			since, found = ts, true
+		}
+	}
+	return since, found, nil
+}
+
+// Compares the names, types and repetition of two schemas, column by column
+func compareSchema(actual, expected *schema.SchemaHandler) error {
+	if len(actual.SchemaElements) != len(expected.SchemaElements) {
+		return fmt.Errorf("%d columns, expected %d", len(actual.SchemaElements)-1, len(expected.SchemaElements)-1)
+	}
+
+	// the first element is the root, named after the struct
+	for i := 1; i < len(expected.SchemaElements); i++ {
+		a, e := actual.SchemaElements[i], expected.SchemaElements[i]
+		name := expected.Infos[i].ExName
+		switch {
+		case actual.Infos[i].ExName != name:
+			return fmt.Errorf("column %d is %q, expected %q", i, actual.Infos[i].ExName, name)
+		case a.GetType() != e.GetType(), a.GetConvertedType() != e.GetConvertedType():
+			return fmt.Errorf("column %q is %s (%s), expected %s (%s)", name, a.GetType(), a.GetConvertedType(), e.GetType(), e.GetConvertedType())
+		case a.GetRepetitionType() != e.GetRepetitionType():
+			return fmt.Errorf("column %q is %s, expected %s", name, a.GetRepetitionType(), e.GetRepetitionType())
+		}
+	}
+	return nil
+}
+
+// Returns the index among the schema's value columns of the field appends are
+// ordered by
+func timestampLeaf(schemaObj any, sh *schema.SchemaHandler) (int, error) {
+	field, err := sinceField(reflect.Indirect(reflect.ValueOf(schemaObj)).Type())
+	if err != nil {
+		return 0, err
+	}
+	// the schema's value columns follow the struct's field order
+	if field >= len(sh.ValueColumns) {
+		return 0, fmt.Errorf("timestamp field %d is not among the schema's %d columns", field, len(sh.ValueColumns))
+	}
+	return field, nil
+}
@@ -0,0 +1,150 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+type appendRecord struct {
+	Timestamp int64  `col:"UTC Timestamp" parquet:"name=utc_timestamp, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	Map       string `col:"Map" parquet:"name=map, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+var appendStart = time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+
+// Returns a record for each day after appendStart
+func appendRecords(days ...int) []*appendRecord {
+	records := make([]*appendRecord, len(days))
+	for i, day := range days {
+		records[i] = &appendRecord{Timestamp: appendStart.AddDate(0, 0, day).UnixMilli(), Map: "Rust"}
+	}
+	return records
+}
+
+// Appends a run of exports, one older than what was already written, to CSV and
+// to parquet part files; each writes only the rows newer than every file so far
+func TestAppend(t *testing.T) {
+	steps := []struct {
+		name        string
+		days        []int
+		wantRows    int
+		wantSkipped int
+		wantPart    string // the parquet file written to, after the table's name
+	}{
+		{name: "first export", days: []int{1, 2, 3}, wantRows: 3, wantPart: ".parquet"},
+		{name: "older export", days: []int{0, 1, 2}, wantSkipped: 3, wantPart: ".part-0001.parquet"},
+		{name: "newer export", days: []int{2, 3, 4, 5}, wantRows: 2, wantSkipped: 2, wantPart: ".part-0001.parquet"},
+		{name: "export newer than a part file", days: []int{5, 6}, wantRows: 1, wantSkipped: 1, wantPart: ".part-0002.parquet"},
+	}
+	wantDays := appendRecords(1, 2, 3, 4, 5, 6)
+
+	encoder, err := NewCSVEncoder(reflect.TypeFor[appendRecord]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	formats := map[string]func(dir string, items []*appendRecord) (*AppendPlan, error){
+		"csv": func(dir string, items []*appendRecord) (*AppendPlan, error) {
+			return PlanCSVAppend(filepath.Join(dir, "matches.csv"), encoder, items)
+		},
+		"parquet": func(dir string, items []*appendRecord) (*AppendPlan, error) {
+			return PlanParquetAppend(filepath.Join(dir, "matches.parquet"), items, new(appendRecord))
+		},
+	}
+
+	for format, plan := range formats {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, step := range steps {
+				p, err := plan(dir, appendRecords(step.days...))
+				if err != nil {
+					t.Fatalf("%s: %v", step.name, err)
+				}
+				if p.Rows != step.wantRows || p.Skipped != step.wantSkipped {
+					t.Errorf("%s: %d rows, %d skipped, want %d and %d", step.name, p.Rows, p.Skipped, step.wantRows, step.wantSkipped)
+				}
+				if want := filepath.Join(dir, "matches"+step.wantPart); format == "parquet" && p.FileName != want {
+					t.Errorf("%s: writing to %s, want %s", step.name, p.FileName, want)
+				}
+				if err := p.Write(); err != nil {
+					t.Fatalf("%s: %v", step.name, err)
+				}
+			}
+
+			var got []int64
+			if format == "csv" {
+				got = csvTimestamps(t, filepath.Join(dir, "matches.csv"))
+			} else {
+				got = parquetTimestamps(t, dir)
+			}
+			var want []int64
+			for _, record := range wantDays {
+				want = append(want, record.Timestamp)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("timestamps written %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func csvTimestamps(t *testing.T, fileName string) []int64 {
+	t.Helper()
+	file, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var timestamps []int64
+	for _, row := range rows[1:] {
+		ts, err := time.Parse(TimestampLayout, row[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		timestamps = append(timestamps, ts.UnixMilli())
+	}
+	return timestamps
+}
+
+// Reads the timestamps of the parquet file and its part files in the directory, in order
+func parquetTimestamps(t *testing.T, dir string) []int64 {
+	t.Helper()
+	files, _, err := parquetParts(filepath.Join(dir, "matches.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var timestamps []int64
+	for _, fileName := range files {
+		pf, err := local.NewLocalFileReader(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pr, err := reader.NewParquetReader(pf, nil, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values, _, _, err := pr.ReadColumnByIndex(0, pr.GetNumRows())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, value := range values {
+			timestamps = append(timestamps, value.(int64))
+		}
+		pr.ReadStop()
+		pf.Close()
+	}
+	return timestamps
+}
@@ -0,0 +1,245 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Maps a record type's tagged fields to the parsers for their columns. Built once
+// per type, then bound to each table's header to parse its rows.
+type Binding[T any] struct {
+	tagName string
+	parsers map[string]FieldParser
+	typ     reflect.Type   // the struct each row is parsed into
+	fields  map[string]int // column name to field index
+	columns []string       // in field order
+	csv     *CSVEncoder
+
+	// creates an empty record, returning it and the struct its fields are set on
+	newRecord func() (*T, reflect.Value)
+}
+
+// Creates a binding for T's fields tagged with tagName, parsed by the parser
+// registered for their column.
+func NewBinding[T any](tagName string, parsers map[string]FieldParser) *Binding[T] {
+	return newBinding(reflect.TypeFor[T](), tagName, parsers, newStruct[T])
+}
+
+func newStruct[T any]() (*T, reflect.Value) {
+	record := new(T)
+	return record, reflect.ValueOf(record).Elem()
+}
+
+// A record whose values are held in a struct built at runtime, e.g. with
+// reflect.StructOf; Struct returns a pointer to that struct.
+type StructRecord interface {
+	Struct() any
+}
+
+// Creates a binding for the fields of the struct type t, built at runtime, whose
+// records wrap each parsed struct. wrap receives a pointer to a new struct.
+func NewDynamicBinding[T any](
+	t reflect.Type,
+	tagName string,
+	parsers map[string]FieldParser,
+	wrap func(reflect.Value) *T,
+) *Binding[T] {
+	return newBinding(t, tagName, parsers, func() (*T, reflect.Value) {
+		ptr := reflect.New(t)
+		return wrap(ptr), ptr.Elem()
+	})
+}
+
+func newBinding[T any](
+	t reflect.Type,
+	tagName string,
+	parsers map[string]FieldParser,
+	newRecord func() (*T, reflect.Value),
+) *Binding[T] {
+	b := parseBinding(t, tagName, parsers, newRecord)
+
+	// bindings are built once per type, typically when a package is initialized;
+	// a field CSV cannot be written from is a programming error
+	csv, err := NewCSVEncoder(t)
+	if err != nil {
+		panic(fmt.Sprintf("helpers: %s: %v", t, err))
+	}
+	b.csv = csv
+	return b
+}
+
+// Creates a binding which parses rows but has no CSV encoder
+func parseBinding[T any](
+	t reflect.Type,
+	tagName string,
+	parsers map[string]FieldParser,
+	newRecord func() (*T, reflect.Value),
+) *Binding[T] {
+	b := &Binding[T]{tagName: tagName, parsers: parsers, typ: t, fields: make(map[string]int), newRecord: newRecord}
+	for i := range t.NumField() {
+		if tag := t.Field(i).Tag.Get(tagName); tag != "" {
+			b.fields[tag] = i
+			b.columns = append(b.columns, tag)
+		}
+	}
+	return b
+}
+
+// Returns the encoder writing T's records as CSV, derived from its parquet tags
+func (b *Binding[T]) CSV() *CSVEncoder {
+	return b.csv
+}
+
+// Assigns a parsed value to a field, reporting false if the value's type cannot
+// be stored in it.
+type fieldSetter func(field reflect.Value, val any) bool
+
+// A column of a particular header, resolved to the field it fills
+type boundColumn struct {
+	column string
+	field  int
+	parser FieldParser
+	set    fieldSetter
+	ptr    bool
+}
+
+// A header resolved against a Binding: the plan for parsing every row of one table.
+type RowBinder[T any] struct {
+	header    []string
+	columns   []boundColumn // in header order; columns matching no field are omitted
+	index     []int         // header position of each entry in columns
+	newRecord func() (*T, reflect.Value)
+}
+
+// Resolves each header cell to its field, parser and setter. Columns matching no
+// field are ignored, and fields whose column is absent keep their zero value; see
+// CompareHeader to detect such drift.
+func (b *Binding[T]) Bind(header []string) (*RowBinder[T], error) {
+	if len(header) == 0 {
+		return nil, errors.New("expected a header with cells, received none")
+	}
+
+	rb := &RowBinder[T]{header: header, newRecord: b.newRecord}
+	t := b.typ
+	for i, column := range header {
+		field, ok := b.fields[column]
+		if !ok {
+			continue
+		}
+
+		parser, ok := b.parsers[column]
+		if !ok {
+			return nil, fmt.Errorf("no parser for column %q", column)
+		}
+
+		fieldType := t.Field(field).Type
+		rb.columns = append(rb.columns, boundColumn{
+			column: column,
+			field:  field,
+			parser: parser,
+			set:    setterFor(fieldType),
+			ptr:    fieldType.Kind() == reflect.Pointer,
+		})
+		rb.index = append(rb.index, i)
+	}
+	return rb, nil
+}
+
+// Parses a row into T following the plan
+func (rb *RowBinder[T]) Parse(row []string) (*T, error) {
+	if len(row) == 0 {
+		return nil, errors.New("expected a row with cells, received none")
+	}
+	if len(rb.header) != len(row) {
+		return nil, fmt.Errorf("expected header (len %d) to equal row (len %d) length", len(rb.header), len(row))
+	}
+
+	result, v := rb.newRecord()
+
+	for i, col := range rb.columns {
+		val, err := col.parser(row[rb.index[i]])
+		if err != nil {
+			return nil, &CellError{Column: col.column, Err: err}
+		}
+
+		// null leaves a pointer field nil; any other field cannot represent it
+		if val == nil {
+			if !col.ptr {
+				return nil, &CellError{Column: col.column, Err: ErrNullValue}
+			}
+			continue
+		}
+
+		field := v.Field(col.field)
+		if !col.set(field, val) {
+			return nil, fmt.Errorf("cannot convert value of type %T to field type %s for column %q", val, field.Type(), col.column)
+		}
+	}
+	return result, nil
+}
+
+// Returns a setter for the field type: a direct assignment for the types the
+// parsers produce, or a reflective conversion for anything else.
+func setterFor(t reflect.Type) fieldSetter {
+	switch t {
+	case reflect.TypeFor[string]():
+		return setValue[string]
+	case reflect.TypeFor[int64]():
+		return setValue[int64]
+	case reflect.TypeFor[float64]():
+		return setValue[float64]
+	case reflect.TypeFor[*string]():
+		return setPointer[string]
+	case reflect.TypeFor[*int64]():
+		return setPointer[int64]
+	case reflect.TypeFor[*float64]():
+		return setPointer[float64]
+	case reflect.TypeFor[time.Duration]():
+		return setValue[time.Duration]
+	case reflect.TypeFor[*time.Duration]():
+		return setPointer[time.Duration]
+	default:
+		return convertValue
+	}
+}
+
+func setValue[V any](field reflect.Value, val any) bool {
+	typed, ok := val.(V)
+	if !ok {
+		return convertValue(field, val)
+	}
+	*field.Addr().Interface().(*V) = typed
+	return true
+}
+
+func setPointer[V any](field reflect.Value, val any) bool {
+	typed, ok := val.(V)
+	if !ok {
+		return convertValue(field, val)
+	}
+	*field.Addr().Interface().(**V) = &typed
+	return true
+}
+
+func convertValue(field reflect.Value, val any) bool {
+	target := field.Type()
+	if target.Kind() == reflect.Pointer {
+		target = target.Elem()
+	}
+
+	valRef := reflect.ValueOf(val)
+	if !valRef.Type().ConvertibleTo(target) {
+		return false
+	}
+
+	if field.Kind() == reflect.Pointer {
+		ptr := reflect.New(target)
+		ptr.Elem().Set(valRef.Convert(target))
+		field.Set(ptr)
+	} else {
+		field.Set(valRef.Convert(target))
+	}
+	return true
+}
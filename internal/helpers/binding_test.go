@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// A record with a field CSV cannot be written from, which only parses
+type listRecord struct {
+	Name string   `col:"Name" parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Tags []string `col:"Tags" parquet:"name=tags, type=LIST"`
+}
+
+var listParsers = map[string]FieldParser{
+	"Name": StringParser(),
+	"Tags": func(s string) (any, error) { return strings.Split(s, ";"), nil },
+}
+
+func TestParseRowReflectWithoutCSV(t *testing.T) {
+	got, err := ParseRowReflect[listRecord]([]string{"Tags", "Name"}, []string{"a;b", "x"}, ColumnTag, listParsers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &listRecord{Name: "x", Tags: []string{"a", "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRowReflect = %+v, want %+v", got, want)
+	}
+}
+
+func TestNewBindingPanicsWithoutCSV(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewBinding accepted a field CSV cannot be written from")
+		}
+	}()
+	NewBinding[listRecord](ColumnTag, listParsers)
+}
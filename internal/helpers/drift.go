@@ -0,0 +1,114 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// The struct tag naming the export column each record field is parsed from
+const ColumnTag = "col"
+
+// Returned, wrapped, when a header differs from its struct under DriftStrict
+var ErrSchemaDrift = errors.New("schema drift")
+
+// Decides how a table whose header differs from its struct's column tags is handled
+type DriftPolicy int
+
+const (
+	// Unknown columns are ignored, and fields for missing columns keep their zero value
+	DriftTolerant DriftPolicy = iota
+	// Any unknown or missing column fails the table
+	DriftStrict
+)
+
+// Compares an observed header with the columns a struct expects
+type HeaderDrift struct {
+	Unknown   []string `json:"unknown,omitempty"`   // in the header, but matching no field
+	Missing   []string `json:"missing,omitempty"`   // expected by a field, but absent from the header
+	Duplicate []string `json:"duplicate,omitempty"` // expected columns appearing more than once
+	Reordered bool     `json:"reordered,omitempty"` // expected columns appear in a different order
+}
+
+// Reports whether the header differs from the struct at all
+func (d HeaderDrift) Any() bool {
+	return len(d.Unknown) > 0 || len(d.Missing) > 0 || len(d.Duplicate) > 0 || d.Reordered
+}
+
+// Compares a table's header against the tagged fields of T, in field order.
+func CompareHeader[T any](header []string, tagName string) HeaderDrift {
+	var expected []string
+	t := reflect.TypeFor[T]()
+	for i := range t.NumField() {
+		if tag := t.Field(i).Tag.Get(tagName); tag != "" {
+			expected = append(expected, tag)
+		}
+	}
+	return compareColumns(header, expected)
+}
+
+// Compares a table's header against the columns expected, in order. A column
+// repeated in the header, e.g. by a colspan, is ordered by its first occurrence.
+func compareColumns(header, expected []string) HeaderDrift {
+	observed := make(map[string]bool, len(header))
+	for _, column := range header {
+		observed[column] = true
+	}
+	known := make(map[string]bool, len(expected))
+	for _, column := range expected {
+		known[column] = true
+	}
+
+	var drift HeaderDrift
+	var present []string
+	seen := make(map[string]bool, len(header))
+	for _, column := range header {
+		switch {
+		case known[column] && seen[column]:
+			if !slices.Contains(drift.Duplicate, column) {
+				drift.Duplicate = append(drift.Duplicate, column)
+			}
+		case known[column]:
+			seen[column] = true
+			present = append(present, column)
+		default:
+			drift.Unknown = append(drift.Unknown, column)
+		}
+	}
+
+	var expectedPresent []string
+	for _, column := range expected {
+		if observed[column] {
+			expectedPresent = append(expectedPresent, column)
+		} else {
+			drift.Missing = append(drift.Missing, column)
+		}
+	}
+
+	for i := range min(len(present), len(expectedPresent)) {
+		if present[i] != expectedPresent[i] {
+			drift.Reordered = true
+			break
+		}
+	}
+
+	return drift
+}
+
+// Returns an error if the drift is not permitted by the policy
+func (p DriftPolicy) check(drift HeaderDrift) error {
+	if p != DriftStrict {
+		return nil
+	}
+	if len(drift.Unknown) > 0 {
+		return fmt.Errorf("%w: unknown columns %q", ErrSchemaDrift, drift.Unknown)
+	}
+	if len(drift.Missing) > 0 {
+		return fmt.Errorf("%w: missing columns %q", ErrSchemaDrift, drift.Missing)
+	}
+	if len(drift.Duplicate) > 0 {
+		return fmt.Errorf("%w: duplicate columns %q", ErrSchemaDrift, drift.Duplicate)
+	}
+	return nil
+}
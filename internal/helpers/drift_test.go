@@ -0,0 +1,114 @@
+package helpers
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+type driftRecord struct {
+	A string `col:"A" parquet:"name=a, type=BYTE_ARRAY, convertedtype=UTF8"`
+	B string `col:"B" parquet:"name=b, type=BYTE_ARRAY, convertedtype=UTF8"`
+	C string `col:"C" parquet:"name=c, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+func TestCompareHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []string
+		want   HeaderDrift
+	}{
+		{"matching", []string{"A", "B", "C"}, HeaderDrift{}},
+		{"unknown", []string{"A", "B", "C", "D"}, HeaderDrift{Unknown: []string{"D"}}},
+		{"missing", []string{"A", "C"}, HeaderDrift{Missing: []string{"B"}}},
+		{"reordered", []string{"B", "A", "C"}, HeaderDrift{Reordered: true}},
+		{"duplicate", []string{"A", "B", "C", "A"}, HeaderDrift{Duplicate: []string{"A"}}},
+		{"duplicate adjacent", []string{"A", "B", "B", "C"}, HeaderDrift{Duplicate: []string{"B"}}},
+		{"duplicate thrice", []string{"A", "B", "B", "B", "C"}, HeaderDrift{Duplicate: []string{"B"}}},
+		{"duplicate and missing", []string{"A", "B", "B"}, HeaderDrift{Missing: []string{"C"}, Duplicate: []string{"B"}}},
+		{"duplicate and reordered", []string{"C", "A", "C", "B"}, HeaderDrift{Duplicate: []string{"C"}, Reordered: true}},
+		{"duplicate unknown", []string{"A", "B", "C", "D", "D"}, HeaderDrift{Unknown: []string{"D", "D"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareHeader[driftRecord](tt.header, ColumnTag)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareHeader(%q) = %+v, want %+v", tt.header, got, tt.want)
+			}
+			if got.Any() != !reflect.DeepEqual(tt.want, HeaderDrift{}) {
+				t.Errorf("Any() = %v for %+v", got.Any(), got)
+			}
+		})
+	}
+}
+
+func TestFromHtmlTableExpandedHeader(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		row       string
+		want      HeaderDrift
+		wantCells []string
+	}{
+		{
+			name:      "colspan",
+			header:    `<th>A</th><th colspan="2">B</th>`,
+			row:       `<td>a</td><td>b1</td><td>b2</td>`,
+			want:      HeaderDrift{Missing: []string{"C"}, Duplicate: []string{"B"}},
+			wantCells: []string{"a", "b2", ""},
+		},
+		{
+			name:      "colspan with every column",
+			header:    `<th>A</th><th colspan="2">B</th><th>C</th>`,
+			row:       `<td>a</td><td>b1</td><td>b2</td><td>c</td>`,
+			want:      HeaderDrift{Duplicate: []string{"B"}},
+			wantCells: []string{"a", "b2", "c"},
+		},
+		{
+			name:      "repeated column",
+			header:    `<th>A</th><th>B</th><th>C</th><th>A</th>`,
+			row:       `<td>a1</td><td>b</td><td>c</td><td>a2</td>`,
+			want:      HeaderDrift{Duplicate: []string{"A"}},
+			wantCells: []string{"a2", "b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := mustDocument(t, `<h1>Game</h1><h2>Matches</h2><table><tr>`+tt.header+`</tr><tr>`+tt.row+`</tr></table>`)
+			binding := NewBinding[driftRecord](ColumnTag, map[string]FieldParser{
+				"A": StringParser(), "B": StringParser(), "C": StringParser(),
+			})
+
+			records, report, err := FromHtmlTable(doc, NewLocator("Game", "Matches"), binding, TableOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(report.Drift, tt.want) {
+				t.Errorf("drift = %+v, want %+v", report.Drift, tt.want)
+			}
+			if len(records) != 1 {
+				t.Fatalf("parsed %d records, want 1", len(records))
+			}
+			if got := []string{records[0].A, records[0].B, records[0].C}; !reflect.DeepEqual(got, tt.wantCells) {
+				t.Errorf("record = %q, want %q", got, tt.wantCells)
+			}
+
+			_, _, err = FromHtmlTable(doc, NewLocator("Game", "Matches"), binding, TableOptions{Drift: DriftStrict})
+			if !errors.Is(err, ErrSchemaDrift) {
+				t.Errorf("strict parse returned %v, want %v", err, ErrSchemaDrift)
+			}
+		})
+	}
+}
+
+func mustDocument(t *testing.T, html string) *goquery.Document {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
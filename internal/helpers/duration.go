@@ -0,0 +1,211 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+// The unit duration columns are written to parquet in, as plain INT64 columns.
+// Parquet has no duration type, its INTERVAL holding only whole milliseconds, so
+// no reader sees the unit in the schema: the _ns suffix of each column's name is
+// the only marker most show. The unit is also recorded in the file's key-value
+// metadata under "<column>.unit", for readers which look.
+const DurationParquetUnit = "nanoseconds"
+
+var errNotADuration = errors.New("not a duration")
+
+// The suffixes accepted after each number of a duration, e.g. "1h 30m" or "90 sec"
+var durationUnits = map[string]time.Duration{
+	"ms": time.Millisecond, "msec": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+}
+
+// Parses durations into a time.Duration from plain seconds, e.g. "5400" or "12.5",
+// clock form, e.g. "1:30:00" or "90:00", or suffixed form, e.g. "1h 30m" or "2 days 3 hours".
+func DurationParser() FieldParser {
+	return func(s string) (any, error) {
+		if IsNull(s) {
+			return nil, nil
+		}
+		return parseDuration(s)
+	}
+}
+
+func parseDuration(cell string) (time.Duration, error) {
+	s := strings.TrimSpace(cell)
+
+	var (
+		d   time.Duration
+		err error
+	)
+	switch {
+	case strings.Contains(s, ":"):
+		d, err = parseClockDuration(s)
+	case strings.IndexFunc(s, isUnitLetter) >= 0:
+		d, err = parseSuffixedDuration(s)
+	default:
+		var seconds float64
+		seconds, err = newNumberFormat(nil).parseFloat(s)
+		d = secondsToDuration(seconds)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", errNotADuration, cell)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%w: %q is negative", errNotADuration, cell)
+	}
+	return d, nil
+}
+
+func isUnitLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Round(seconds * float64(time.Second)))
+}
+
+// Parses "h:mm:ss" or "mm:ss", where the seconds may have a fraction and the
+// leading field may exceed its usual range, e.g. "125:00:00"
+func parseClockDuration(s string) (time.Duration, error) {
+	fields := strings.Split(s, ":")
+	if len(fields) > 3 {
+		return 0, errNotADuration
+	}
+
+	var d time.Duration
+	for i, field := range fields {
+		last := i == len(fields)-1
+		if field == "" || (i > 0 && (len(field) < 2 || (!last && len(field) != 2))) {
+			return 0, errNotADuration
+		}
+
+		if last {
+			seconds, err := strconv.ParseFloat(field, 64)
+			if err != nil || seconds < 0 || (i > 0 && seconds >= 60) {
+				return 0, errNotADuration
+			}
+			d = d*60 + secondsToDuration(seconds)
+			continue
+		}
+
+		n, err := strconv.ParseUint(field, 10, 32)
+		if err != nil || (i > 0 && n >= 60) {
+			return 0, errNotADuration
+		}
+		d = d*60 + time.Duration(n)*time.Second
+	}
+	return d, nil
+}
+
+// Parses a sequence of numbers each followed by a unit, e.g. "1h30m" or "2 days 3 hours"
+func parseSuffixedDuration(s string) (time.Duration, error) {
+	var d time.Duration
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		end := strings.IndexFunc(s, isUnitLetter)
+		if end <= 0 {
+			return 0, errNotADuration
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(strings.ReplaceAll(s[:end], ",", ".")), 64)
+		if err != nil || n < 0 {
+			return 0, errNotADuration
+		}
+		s = s[end:]
+
+		end = strings.IndexFunc(s, func(r rune) bool { return !isUnitLetter(r) })
+		if end < 0 {
+			end = len(s)
+		}
+		unit, ok := durationUnits[strings.ToLower(s[:end])]
+		if !ok {
+			return 0, errNotADuration
+		}
+		s = s[end:]
+
+		d += time.Duration(math.Round(n * float64(unit)))
+	}
+	return d, nil
+}
+
+// Formats a nullable duration for CSV as seconds, without trailing zeros, writing
+// null as an empty cell
+func FormatDurationSeconds(v *time.Duration) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(v.Seconds(), 'f', -1, 64)
+}
+
+// Formats a nullable duration for CSV in ISO-8601, e.g. "PT1H30M5.5S", writing null
+// as an empty cell. Days are written as hours, as a day's length is not fixed.
+func FormatDurationISO(v *time.Duration) string {
+	if v == nil {
+		return ""
+	}
+	d := *v
+	if d == 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	b.WriteString("PT")
+	if hours := d / time.Hour; hours > 0 {
+		fmt.Fprintf(&b, "%dH", hours)
+		d -= hours * time.Hour
+	}
+	if minutes := d / time.Minute; minutes > 0 {
+		fmt.Fprintf(&b, "%dM", minutes)
+		d -= minutes * time.Minute
+	}
+	if d > 0 {
+		b.WriteString(strconv.FormatFloat(d.Seconds(), 'f', -1, 64))
+		b.WriteByte('S')
+	}
+	return b.String()
+}
+
+// Records the unit of each time.Duration field of the schema struct, by its
+// parquet column name
+func durationMetadata(schemaObj any) []*parquet.KeyValue {
+	t := reflect.Indirect(reflect.ValueOf(schemaObj)).Type()
+
+	var metadata []*parquet.KeyValue
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Type != reflect.TypeFor[time.Duration]() && field.Type != reflect.TypeFor[*time.Duration]() {
+			continue
+		}
+		name := parquetName(field.Tag.Get("parquet"))
+		if name == "" {
+			continue
+		}
+		unit := DurationParquetUnit
+		metadata = append(metadata, &parquet.KeyValue{Key: name + ".unit", Value: &unit})
+	}
+	return metadata
+}
+
+// Returns the column name from a parquet struct tag
+func parquetName(tag string) string {
+	return parquetTagValue(tag, "name")
+}
+
+// Returns the value of one key of a parquet struct tag, e.g. "convertedtype"
+func parquetTagValue(tag, name string) string {
+	for _, part := range strings.Split(tag, ",") {
+		if key, val, ok := strings.Cut(strings.TrimSpace(part), "="); ok && strings.TrimSpace(key) == name {
+			return strings.TrimSpace(val)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,305 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// The number of cells sampled per column when inferring its type
+const inferenceSampleSize = 1000
+
+// The type of a column in a table with no registered handler, inferred from its cells
+type ColumnType int
+
+const (
+	ColumnString ColumnType = iota
+	ColumnInt
+	ColumnFloat
+	ColumnPercent
+	ColumnTimestamp
+)
+
+func (t ColumnType) String() string {
+	switch t {
+	case ColumnInt:
+		return "int"
+	case ColumnFloat:
+		return "float"
+	case ColumnPercent:
+		return "percent"
+	case ColumnTimestamp:
+		return "timestamp"
+	default:
+		return "string"
+	}
+}
+
+// Infers a column's type by sampling its non-null cells with the same parsers used
+// by the table packages. A type is only chosen if every sampled cell parses as it.
+func InferColumnType(cells []string) ColumnType {
+	sample := sampleCells(cells)
+	if len(sample) == 0 {
+		return ColumnString
+	}
+
+	if allParse(sample, TimeParser()) {
+		return ColumnTimestamp
+	}
+	// the numeric parsers accept a percent suffix, so check for it first
+	if allPercent(sample) && allParse(sample, FloatParser()) {
+		return ColumnPercent
+	}
+	if allParse(sample, IntParser()) {
+		return ColumnInt
+	}
+	if allParse(sample, FloatParser()) {
+		return ColumnFloat
+	}
+	return ColumnString
+}
+
+// Returns up to inferenceSampleSize non-null cells, spread evenly across the column.
+func sampleCells(cells []string) []string {
+	var nonEmpty []string
+	for _, cell := range cells {
+		if !IsNull(cell) {
+			nonEmpty = append(nonEmpty, strings.TrimSpace(cell))
+		}
+	}
+	if len(nonEmpty) <= inferenceSampleSize {
+		return nonEmpty
+	}
+
+	sample := make([]string, 0, inferenceSampleSize)
+	step := float64(len(nonEmpty)) / inferenceSampleSize
+	for i := range inferenceSampleSize {
+		sample = append(sample, nonEmpty[int(float64(i)*step)])
+	}
+	return sample
+}
+
+func allPercent(cells []string) bool {
+	for _, cell := range cells {
+		if !strings.HasSuffix(cell, "%") {
+			return false
+		}
+	}
+	return true
+}
+
+func allParse(cells []string, parser FieldParser) bool {
+	for _, cell := range cells {
+		if _, err := parser(cell); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// A table with no registered handler, kept as raw cells alongside inferred column types.
+type GenericTable struct {
+	H1, H2  string
+	Name    string
+	Header  []string
+	Columns []string
+	Types   []ColumnType
+	Rows    [][]string
+}
+
+// Builds a generic table, inferring the type of each column and naming the table
+// and its columns after the headings and header cells.
+func NewGenericTable(h1, h2 string, header []string, rows [][]string) *GenericTable {
+	g := &GenericTable{
+		H1:     h1,
+		H2:     h2,
+		Name:   ToSnakeCase(h1 + " " + h2),
+		Header: header,
+		Rows:   rows,
+	}
+
+	seen := make(map[string]int)
+	for i, label := range header {
+		column := ToSnakeCase(label)
+		if column == "" {
+			column = fmt.Sprintf("column_%d", i+1)
+		}
+		if seen[column]++; seen[column] > 1 {
+			column = fmt.Sprintf("%s_%d", column, seen[column])
+		}
+		g.Columns = append(g.Columns, column)
+
+		cells := make([]string, 0, len(rows))
+		for _, row := range rows {
+			if i < len(row) {
+				cells = append(cells, row[i])
+			}
+		}
+		g.Types = append(g.Types, InferColumnType(cells))
+	}
+
+	return g
+}
+
+// Appends the rows not already present in the table, compared cell by cell, and
+// infers the column types afresh. Returns how many rows were new and how many overlapped.
+func (g *GenericTable) MergeRows(rows [][]string) (added, overlapping int) {
+	seen := make(map[string]bool, len(g.Rows)+len(rows))
+	for _, row := range g.Rows {
+		seen[strings.Join(row, "\x1f")] = true
+	}
+	for _, row := range rows {
+		key := strings.Join(row, "\x1f")
+		if seen[key] {
+			overlapping++
+			continue
+		}
+		seen[key] = true
+		g.Rows = append(g.Rows, row)
+		added++
+	}
+
+	merged := NewGenericTable(g.H1, g.H2, g.Header, g.Rows)
+	g.Types = merged.Types
+	return added, overlapping
+}
+
+// Converts free text, e.g. an H1/H2 title or column header, into a snake_case identifier.
+func ToSnakeCase(s string) string {
+	var b strings.Builder
+	pendingSep := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingSep && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			pendingSep = false
+			b.WriteRune(r)
+		} else {
+			pendingSep = true
+		}
+	}
+	return b.String()
+}
+
+// Converts a cell to the value written for its column type, or nil if it is null
+// or does not conform to the type inferred from the sample.
+func (g *GenericTable) value(col int, cell string) any {
+	if IsNull(cell) {
+		return nil
+	}
+
+	var (
+		val any
+		err error
+	)
+	switch g.Types[col] {
+	case ColumnTimestamp:
+		val, err = TimestampToUnixMillisInt64()(cell)
+	case ColumnInt:
+		val, err = IntParser()(cell)
+	case ColumnFloat, ColumnPercent:
+		val, err = FloatParser()(cell)
+	default:
+		val = cell
+	}
+	if err != nil {
+		return nil
+	}
+	return val
+}
+
+// Writes the table to CSV in the output directory, named after its headings.
+// Timestamps are normalized to TimestampLayout as in the table packages, and null cells in
+// typed columns are written empty; other cells are kept as-is.
+func (g *GenericTable) ToCSV(outputDir string) error {
+	file, err := os.Create(path.Join(outputDir, g.Name+".csv"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	defer w.Flush()
+
+	if err := w.Write(g.Columns); err != nil {
+		return err
+	}
+
+	for _, row := range g.Rows {
+		record := make([]string, len(g.Columns))
+		for i := range g.Columns {
+			if i >= len(row) {
+				continue
+			}
+			record[i] = row[i]
+			if g.Types[i] != ColumnString && IsNull(row[i]) {
+				record[i] = ""
+			} else if g.Types[i] == ColumnTimestamp {
+				if ms, ok := g.value(i, row[i]).(int64); ok {
+					record[i] = FormatUnixMillis(ms)
+				}
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes the table to parquet in the output directory, named after its headings.
+// Every column is OPTIONAL; null or non-conforming cells are written as null.
+func (g *GenericTable) ToParquet(outputDir string) error {
+	fw, err := os.Create(path.Join(outputDir, g.Name+".parquet"))
+	if err != nil {
+		return err
+	}
+	defer fw.Close()
+
+	pw, err := writer.NewCSVWriterFromWriter(g.parquetMetadata(), fw, 4)
+	if err != nil {
+		return err
+	}
+	pw.RowGroupSize = 128 * 1024 * 1024 // 128MB
+	pw.CompressionType = parquet.CompressionCodec_UNCOMPRESSED
+
+	for _, row := range g.Rows {
+		record := make([]any, len(g.Columns))
+		for i := range g.Columns {
+			if i < len(row) {
+				record[i] = g.value(i, row[i])
+			}
+		}
+		if err := pw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return pw.WriteStop()
+}
+
+func (g *GenericTable) parquetMetadata() []string {
+	md := make([]string, len(g.Columns))
+	for i, column := range g.Columns {
+		var typ string
+		switch g.Types[i] {
+		case ColumnTimestamp:
+			typ = "type=INT64, convertedtype=TIMESTAMP_MILLIS"
+		case ColumnInt:
+			typ = "type=INT64"
+		case ColumnFloat, ColumnPercent:
+			typ = "type=DOUBLE"
+		default:
+			typ = "type=BYTE_ARRAY, convertedtype=UTF8"
+		}
+		md[i] = fmt.Sprintf("name=%s, %s, repetitiontype=OPTIONAL", column, typ)
+	}
+	return md
+}
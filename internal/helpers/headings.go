@@ -0,0 +1,136 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Matches a heading's text against any of several titles, compared as
+// HeadingMatches does, or regular expressions, tested against the trimmed text.
+type HeadingMatcher struct {
+	Titles   []string
+	Patterns []*regexp.Regexp
+}
+
+func (m *HeadingMatcher) Matches(text string) bool {
+	for _, title := range m.Titles {
+		if HeadingMatches(text, title) {
+			return true
+		}
+	}
+	trimmed := strings.TrimSpace(text)
+	for _, pattern := range m.Patterns {
+		if pattern.MatchString(trimmed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Describes the matcher for error messages, e.g. `"Title" | "Alias" | /pattern/`
+func (m *HeadingMatcher) String() string {
+	var parts []string
+	for _, title := range m.Titles {
+		parts = append(parts, fmt.Sprintf("%q", title))
+	}
+	for _, pattern := range m.Patterns {
+		parts = append(parts, "/"+pattern.String()+"/")
+	}
+	return strings.Join(parts, " | ")
+}
+
+// Locates a table within an export by the H1 and H2 headings it falls under
+type Locator struct {
+	H1 HeadingMatcher
+	H2 HeadingMatcher
+}
+
+// Creates a locator matching exactly the provided titles, ignoring case
+func NewLocator(h1Text, h2Text string) *Locator {
+	return &Locator{
+		H1: HeadingMatcher{Titles: []string{h1Text}},
+		H2: HeadingMatcher{Titles: []string{h2Text}},
+	}
+}
+
+// Adds alias titles to the H2 matcher, returning the locator for chaining
+func (l *Locator) WithH2Aliases(titles ...string) *Locator {
+	l.H2.Titles = append(l.H2.Titles, titles...)
+	return l
+}
+
+// Adds regular expressions to the H2 matcher, returning the locator for chaining.
+// Panics if a pattern does not compile, as regexp.MustCompile does.
+func (l *Locator) WithH2Patterns(patterns ...string) *Locator {
+	for _, pattern := range patterns {
+		l.H2.Patterns = append(l.H2.Patterns, regexp.MustCompile(pattern))
+	}
+	return l
+}
+
+// Returns a pattern matching the title with any trailing parenthetical qualifier,
+// or none, ignoring case; e.g. "Zombies Data" matches "Zombies Data (chronological)".
+// Exports have dropped and reworded these qualifiers, so a table's locator adds
+// this pattern to its H2 to keep finding the section when they do.
+func QualifiedTitlePattern(title string) string {
+	return `(?i)^` + regexp.QuoteMeta(title) + `(\s*\(.*\))?$`
+}
+
+func (l *Locator) Matches(h1, h2 string) bool {
+	return l.H1.Matches(h1) && l.H2.Matches(h2)
+}
+
+// Additional titles and patterns for one heading, as read from an override file
+type HeadingOverride struct {
+	Titles   []string `json:"titles"`
+	Patterns []string `json:"patterns"`
+}
+
+// Additional H1 and H2 matches for one table, as read from an override file
+type LocatorOverride struct {
+	H1 HeadingOverride `json:"h1"`
+	H2 HeadingOverride `json:"h2"`
+}
+
+// Extends the locator with the override's titles and patterns; the built-in
+// titles continue to match.
+func (l *Locator) Extend(o LocatorOverride) error {
+	if err := l.H1.extend(o.H1); err != nil {
+		return fmt.Errorf("h1: %w", err)
+	}
+	if err := l.H2.extend(o.H2); err != nil {
+		return fmt.Errorf("h2: %w", err)
+	}
+	return nil
+}
+
+func (m *HeadingMatcher) extend(o HeadingOverride) error {
+	for _, pattern := range o.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		m.Patterns = append(m.Patterns, re)
+	}
+	m.Titles = append(m.Titles, o.Titles...)
+	return nil
+}
+
+// Reads a JSON file of heading overrides, keyed by table name, e.g.
+//
+//	{"warzone2": {"h2": {"titles": ["Match Data"], "patterns": ["(?i)^multiplayer"]}}}
+func LoadLocatorOverrides(fileName string) (map[string]LocatorOverride, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides map[string]LocatorOverride
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse heading overrides %s: %w", fileName, err)
+	}
+	return overrides, nil
+}
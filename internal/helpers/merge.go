@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A record with a natural key, identifying it across overlapping exports
+type Keyed interface {
+	Key() string
+}
+
+// Joins the values identifying a record into a key for deduplication
+func NaturalKey(values ...any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, "\x1f")
+}
+
+// Appends the incoming records whose key is not already present, keeping the
+// existing records first and the rest in their incoming order. Returns the merged
+// records and how many incoming records were new and how many overlapped.
+func MergeRecords[S ~[]R, R Keyed](existing, incoming S) (merged S, added, overlapping int) {
+	seen := make(map[string]bool, len(existing)+len(incoming))
+	for _, record := range existing {
+		seen[record.Key()] = true
+	}
+
+	merged = existing
+	for _, record := range incoming {
+		key := record.Key()
+		if seen[key] {
+			overlapping++
+			continue
+		}
+		seen[key] = true
+		merged = append(merged, record)
+		added++
+	}
+	return merged, added, overlapping
+}
@@ -0,0 +1,24 @@
+package helpers
+
+import (
+	"errors"
+	"strings"
+)
+
+// Returned, wrapped, when a null cell is parsed into a field which cannot hold null
+var ErrNullValue = errors.New("null value for non-nullable field")
+
+// Cell contents which mean a value was not recorded, compared case-insensitively
+var nullTokens = []string{"", "-", "n/a", "null"}
+
+// Reports whether a cell holds no value, e.g. "", "-", "N/A" or "null".
+// Such cells parse to nil, which maps onto pointer fields and OPTIONAL parquet columns.
+func IsNull(cell string) bool {
+	cell = strings.TrimSpace(cell)
+	for _, token := range nullTokens {
+		if strings.EqualFold(cell, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Keeps every digit of a parsed float, rather than rounding it
+const FullPrecision = -1
+
+// How a float is rounded to its column's precision
+type Rounding int
+
+const (
+	// Halves are rounded away from zero, e.g. 0.25 to 0.3
+	RoundHalfAwayFromZero Rounding = iota
+	// Halves are rounded to the nearest even digit, e.g. 0.25 to 0.2
+	RoundHalfEven
+	// Digits beyond the precision are dropped, e.g. 0.29 to 0.2
+	RoundTruncate
+)
+
+// How a column's numbers are written and kept
+type numberFormat struct {
+	decimal   rune // 0 to infer from each cell
+	precision int
+	rounding  Rounding
+}
+
+// Configures a numeric FieldParser for one column
+type NumberOption func(*numberFormat)
+
+// Reads numbers with the given decimal mark, '.' or ',', treating the other as a
+// thousands separator. Without this option the mark is inferred from each cell.
+func WithDecimalMark(mark rune) NumberOption {
+	return func(f *numberFormat) { f.decimal = mark }
+}
+
+// Rounds floats to the given number of decimals, or keeps every digit for FullPrecision
+func WithPrecision(decimals int, rounding Rounding) NumberOption {
+	return func(f *numberFormat) { f.precision, f.rounding = decimals, rounding }
+}
+
+func newNumberFormat(opts []NumberOption) numberFormat {
+	f := numberFormat{precision: FullPrecision}
+	for _, opt := range opts {
+		opt(&f)
+	}
+	return f
+}
+
+var errNotANumber = errors.New("not a number")
+
+// Rewrites a cell as a plain number strconv accepts, e.g. "-1234.5" from
+// "−1.234,5 %". Accepts a leading sign, including the Unicode minus, a trailing
+// percent sign, and thousands separated by ',', '.', spaces or apostrophes.
+func normalizeNumber(cell string, decimal rune) (string, error) {
+	s := strings.TrimSpace(cell)
+	s = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+
+	var b strings.Builder
+	switch {
+	case strings.HasPrefix(s, "-"):
+		b.WriteByte('-')
+		s = s[1:]
+	case strings.HasPrefix(s, "−"):
+		b.WriteByte('-')
+		s = s[len("−"):]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	if decimal == 0 {
+		decimal = inferDecimalMark(s)
+	}
+
+	digits, seenDecimal, prevDigit := 0, false, false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+			digits++
+			prevDigit = true
+			continue
+		case r == decimal && !seenDecimal:
+			b.WriteByte('.')
+			seenDecimal = true
+		case !seenDecimal && prevDigit && isGroupSeparator(r, decimal):
+			// a separator must fall between digits
+		default:
+			return "", fmt.Errorf("%w: %q", errNotANumber, cell)
+		}
+		prevDigit = false
+	}
+	if digits == 0 || (!prevDigit && !seenDecimal) {
+		return "", fmt.Errorf("%w: %q", errNotANumber, cell)
+	}
+	return b.String(), nil
+}
+
+func isGroupSeparator(r, decimal rune) bool {
+	switch r {
+	case '.', ',':
+		return r != decimal
+	case '\'', '’':
+		return true
+	default:
+		return unicode.IsSpace(r) || r == '\u202f' // narrow no-break space
+	}
+}
+
+// Infers a cell's decimal mark, the same way for every parser: the last of '.' and
+// ',' when both appear. A mark appearing more than once is a thousands separator,
+// as in "1.234.567". A mark appearing once is a thousands separator only if it is
+// followed by exactly three digits and preceded by a group which could lead a
+// grouped number, of one to three digits not starting with 0, as in "1,234" or
+// "1.234"; otherwise it is the decimal mark, as in "0,500", "1234,567",
+// "1 234,567" or "1.5".
+//
+// A number with exactly three decimals, e.g. "1.234" meaning 1.234, is therefore
+// read as a whole number; columns holding such numbers should fix their mark with
+// WithDecimalMark.
+func inferDecimalMark(s string) rune {
+	dot, comma := strings.LastIndexByte(s, '.'), strings.LastIndexByte(s, ',')
+	switch {
+	case dot >= 0 && comma >= 0:
+		if dot > comma {
+			return '.'
+		}
+		return ','
+	case dot >= 0:
+		if groupsThousands(s, '.', dot) {
+			return ','
+		}
+		return '.'
+	case comma >= 0:
+		if groupsThousands(s, ',', comma) {
+			return '.'
+		}
+		return ','
+	default:
+		return '.'
+	}
+}
+
+// Reports whether the only kind of mark in s, last found at i, separates thousands
+func groupsThousands(s string, mark byte, i int) bool {
+	if strings.Count(s, string(mark)) > 1 {
+		return true
+	}
+	if len(s)-i-1 != 3 {
+		return false
+	}
+
+	// a number already grouped by another separator, as in "1 234,567", has no
+	// group to lead
+	lead := s[:i]
+	if strings.IndexFunc(lead, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return false
+	}
+	return len(lead) >= 1 && len(lead) <= 3 && lead[0] != '0'
+}
+
+// Parses a whole number across the full int64 range
+func (f numberFormat) parseInt(cell string) (int64, error) {
+	s, err := normalizeNumber(cell, f.decimal)
+	if err != nil {
+		return 0, err
+	}
+
+	// a whole number may still be written with zero decimals, e.g. "1,234.00"
+	if whole, fraction, ok := strings.Cut(s, "."); ok && strings.Trim(fraction, "0") == "" {
+		s = whole
+	}
+
+	i, err := strconv.ParseInt(s, 10, 64)
+	if numErr := (*strconv.NumError)(nil); errors.As(err, &numErr) {
+		return 0, fmt.Errorf("invalid integer %q: %w", cell, numErr.Err)
+	}
+	return i, err
+}
+
+// Parses a float at 64-bit precision, then rounds it to the column's precision
+func (f numberFormat) parseFloat(cell string) (float64, error) {
+	s, err := normalizeNumber(cell, f.decimal)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if numErr := (*strconv.NumError)(nil); errors.As(err, &numErr) {
+		return 0, fmt.Errorf("invalid number %q: %w", cell, numErr.Err)
+	}
+	return f.round(v), nil
+}
+
+func (f numberFormat) round(v float64) float64 {
+	if f.precision < 0 {
+		return v
+	}
+
+	scale := math.Pow10(f.precision)
+	switch f.rounding {
+	case RoundHalfEven:
+		return math.RoundToEven(v*scale) / scale
+	case RoundTruncate:
+		return math.Trunc(v*scale) / scale
+	default:
+		return math.Round(v*scale) / scale
+	}
+}
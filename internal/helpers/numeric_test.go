@@ -0,0 +1,107 @@
+package helpers
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// Every numeric parser reads a cell with the same decimal mark
+func TestDecimalMarkInference(t *testing.T) {
+	tests := []struct {
+		cell string
+		want float64
+	}{
+		// a lone mark before exactly three digits, after a group which can lead
+		{"1,234", 1234},
+		{"1.234", 1234},
+		{"999,999", 999999},
+		{"-1.234", -1234},
+
+		// a lone mark which cannot be grouping
+		{"0,500", 0.5},
+		{"0.500", 0.5},
+		{"1234,567", 1234.567},
+		{"1234.567", 1234.567},
+		{"012,345", 12.345},
+		{"1 234,567", 1234.567},
+		{"1'234.567", 1234.567},
+		{"1,5", 1.5},
+		{"1.25", 1.25},
+		{"1,2345", 1.2345},
+		{",500", 0.5},
+
+		// a repeated mark, or both marks
+		{"1.234.567", 1234567},
+		{"1,234,567", 1234567},
+		{"1.234,5", 1234.5},
+		{"1,234.5", 1234.5},
+		{"1.234.567,89", 1234567.89},
+
+		// no mark
+		{"1234", 1234},
+		{"+42 %", 42},
+		{"−7", -7},
+	}
+
+	ints, floats, durations := IntParser(), FloatParser(), DurationParser()
+	for _, tt := range tests {
+		t.Run(tt.cell, func(t *testing.T) {
+			f, err := floats(tt.cell)
+			if err != nil {
+				t.Fatalf("FloatParser: %v", err)
+			}
+			if f != tt.want {
+				t.Errorf("FloatParser = %v, want %v", f, tt.want)
+			}
+
+			i, err := ints(tt.cell)
+			if tt.want == math.Trunc(tt.want) {
+				if err != nil || i != int64(tt.want) {
+					t.Errorf("IntParser = %v, %v, want %v", i, err, int64(tt.want))
+				}
+			} else if err == nil {
+				t.Errorf("IntParser = %v, want an error for a fraction", i)
+			}
+
+			if tt.want < 0 {
+				return
+			}
+			d, err := durations(tt.cell)
+			if err != nil {
+				t.Fatalf("DurationParser: %v", err)
+			}
+			if want := time.Duration(tt.want * float64(time.Second)); d != want {
+				t.Errorf("DurationParser = %v, want %v", d, want)
+			}
+		})
+	}
+}
+
+func TestFixedDecimalMark(t *testing.T) {
+	tests := []struct {
+		mark rune
+		cell string
+		want float64
+	}{
+		{'.', "1.234", 1.234},
+		{'.', "1,234", 1234},
+		{',', "1,234", 1.234},
+		{',', "1.234", 1234},
+		{',', "0,500", 0.5},
+	}
+	for _, tt := range tests {
+		got, err := FloatParser(WithDecimalMark(tt.mark))(tt.cell)
+		if err != nil || got != tt.want {
+			t.Errorf("FloatParser(%q)(%q) = %v, %v, want %v", tt.mark, tt.cell, got, err, tt.want)
+		}
+	}
+}
+
+func TestNotANumber(t *testing.T) {
+	for _, cell := range []string{"abc", "1,,234", "1,234x", "--1", "1.2.3,4,5"} {
+		if v, err := FloatParser()(cell); err == nil {
+			t.Errorf("FloatParser(%q) = %v, want an error", cell, v)
+		}
+	}
+}
@@ -3,15 +3,13 @@ package helpers
 import (
 	"errors"
 	"fmt"
-	"math"
 	"reflect"
-	"strconv"
-	"strings"
-	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
+// Parses a cell into a field's value. Parsers for nullable types return nil, with
+// no error, for cells where IsNull holds.
 type FieldParser func(string) (any, error)
 
 func StringParser() FieldParser {
@@ -20,27 +18,51 @@ func StringParser() FieldParser {
 	}
 }
 
-func IntParser() FieldParser {
+// Parses whole numbers across the int64 range, accepting thousands separators,
+// signs and a percent suffix; see NumberOption to fix the decimal mark.
+func IntParser(opts ...NumberOption) FieldParser {
+	format := newNumberFormat(opts)
 	return func(s string) (any, error) {
-		return tryParseInt(s)
+		if IsNull(s) {
+			return nil, nil
+		}
+		return format.parseInt(s)
 	}
 }
 
-func FloatParser() FieldParser {
+// Parses floats at full precision unless WithPrecision is given, accepting
+// thousands separators, either decimal mark, signs and a percent suffix.
+func FloatParser(opts ...NumberOption) FieldParser {
+	format := newNumberFormat(opts)
 	return func(s string) (any, error) {
-		return tryParseFloat(s)
+		if IsNull(s) {
+			return nil, nil
+		}
+		return format.parseFloat(s)
 	}
 }
 
-func TimeParser() FieldParser {
+// Parses timestamps into UTC times with DefaultTimeLayouts unless WithLayouts is
+// given; see TimeOption.
+func TimeParser(opts ...TimeOption) FieldParser {
+	format := newTimeFormat(opts)
 	return func(s string) (any, error) {
-		return tryParseTimeUTC(s)
+		if IsNull(s) {
+			return nil, nil
+		}
+		return format.parse(s)
 	}
 }
 
-func TimestampToUnixMillisInt64() FieldParser {
+// Parses timestamps as TimeParser does into unix milliseconds, keeping any
+// fraction of a second down to the millisecond.
+func TimestampToUnixMillisInt64(opts ...TimeOption) FieldParser {
+	format := newTimeFormat(opts)
 	return func(s string) (any, error) {
-		ts, err := tryParseTimeUTC(s)
+		if IsNull(s) {
+			return nil, nil
+		}
+		ts, err := format.parse(s)
 		if err != nil {
 			return 0, err
 		}
@@ -48,129 +70,53 @@ func TimestampToUnixMillisInt64() FieldParser {
 	}
 }
 
-func tryParseTimeUTC(ts string) (time.Time, error) {
-	t, err := time.Parse("2006-01-02 15:04:05", strings.TrimSpace(ts))
-	if err != nil {
-		return time.Time{}, err
-	}
-	return t.UTC(), nil
-}
-
-func tryParseFloat(ts string) (float64, error) {
-	// trim off a percentage
-	tsClean := strings.TrimSuffix(ts, "%")
-
-	t, err := strconv.ParseFloat(tsClean, 32)
-	if err != nil {
-		return 0.0, err
-	}
-
-	return math.Round(t*100) / 100, nil
-}
-
-func tryParseInt(ts string) (int64, error) {
-	i, err := strconv.ParseInt(ts, 10, 32)
-	if err != nil {
-		return 0, err
-	}
-
-	return i, nil
-}
-
-func MakeFromRow[T any](tagName string, parsers map[string]FieldParser) func([]string, []string) (*T, error) {
-	return func(header, row []string) (*T, error) {
-		if len(header) == 0 {
-			return nil, errors.New("expected a header with cells, received none")
-		}
-
-		if len(row) == 0 {
-			return nil, errors.New("expected a row with cells, received none")
-		}
-
-		if len(header) != len(row) {
-			return nil, fmt.Errorf("expected header (len %d) to equal row (len %d) length", len(header), len(row))
-		}
-
-		return ParseRowReflect[T](header, row, tagName, parsers)
-	}
-}
-
+// Parses the table under the provided headings into records. Rows which fail to
+// parse are quarantined in the returned report, until they exceed the error budget.
 func FromHtmlTable[T any](
 	doc *goquery.Document,
-	h1Text, h2Text string,
-	fromRow func([]string, []string) (*T, error),
-) ([]*T, error) {
-	header, rows, err := FindTable(doc, h1Text, h2Text)
+	loc *Locator,
+	binding *Binding[T],
+	opts TableOptions,
+) ([]*T, TableReport, error) {
+	header, rows, err := FindTable(doc, loc)
 	if err != nil {
-		return nil, err
+		return nil, TableReport{}, err
 	}
 	if len(header) == 0 {
-		return nil, errors.New("header row not found")
+		return nil, TableReport{}, errors.New("header row not found")
 	}
 	if len(rows) == 0 {
-		return nil, errors.New("no rows found")
+		return nil, TableReport{Header: header}, ErrNoRows
 	}
 
-	var result []*T
-	for i, row := range rows {
-		res, err := fromRow(header, row)
-		if err != nil {
-			return nil, fmt.Errorf("row %d: %w", i+1, err)
-		}
-		if res == nil {
-			return nil, fmt.Errorf("row %d: nul result", i+1)
+	reader, err := newTableReader(header, binding, opts)
+	if err != nil {
+		return nil, reader.report, err
+	}
+	for _, row := range rows {
+		if err := reader.add(row); err != nil {
+			return nil, reader.report, err
 		}
-		result = append(result, res)
 	}
-	return result, nil
+	if err := reader.finish(); err != nil {
+		return nil, reader.report, err
+	}
+	return reader.records, reader.report, nil
 }
 
+// Parses a single row into T, matching each column to the field tagged with its
+// name. The header is resolved afresh for every row, and T needs no CSV encoding.
+//
+// Deprecated: bind the header once with NewBinding and Binding.Bind, and parse
+// every row with the RowBinder.
 func ParseRowReflect[T any](header []string, row []string, tagName string, fieldParsers map[string]FieldParser) (*T, error) {
 	if len(header) != len(row) {
 		return nil, fmt.Errorf("header was length %d, row was length %d, expected a match", len(header), len(row))
 	}
 
-	var result T
-	v := reflect.ValueOf(&result).Elem()
-	t := v.Type()
-
-	for i, column := range header {
-		cell := row[i]
-
-		// Match struct field by tag
-		var field reflect.StructField
-		var found bool
-		for j := range t.NumField() {
-			if t.Field(j).Tag.Get(tagName) == column {
-				field = t.Field(j)
-				found = true
-				break
-			}
-		}
-		if !found {
-			return nil, fmt.Errorf("field with tag %q not found in struct", column)
-		}
-
-		parser, ok := fieldParsers[column]
-		if !ok {
-			return nil, fmt.Errorf("no parser for column %q", column)
-		}
-
-		val, err := parser(cell)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing field %q: %v", column, err)
-		}
-
-		fieldVal := v.FieldByName(field.Name)
-		if !fieldVal.CanSet() {
-			return nil, fmt.Errorf("cannot set field %s", field.Name)
-		}
-
-		valRef := reflect.ValueOf(val)
-		if !valRef.Type().ConvertibleTo(fieldVal.Type()) {
-			return nil, fmt.Errorf("cannot convert value of type %s to field type %s for column %q", valRef.Type(), fieldVal.Type(), column)
-		}
-		fieldVal.Set(valRef.Convert(fieldVal.Type()))
+	binder, err := parseBinding(reflect.TypeFor[T](), tagName, fieldParsers, newStruct[T]).Bind(header)
+	if err != nil {
+		return nil, err
 	}
-	return &result, nil
+	return binder.Parse(row)
 }
@@ -0,0 +1,153 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Returned, wrapped, when a table rejects more rows than its error budget allows
+var ErrBudgetExceeded = errors.New("error budget exceeded")
+
+// A failure to parse a single cell, naming the column it belongs to
+type CellError struct {
+	Column string
+	Err    error
+}
+
+func (e *CellError) Error() string {
+	return fmt.Sprintf("error parsing field %q: %v", e.Column, e.Err)
+}
+
+func (e *CellError) Unwrap() error {
+	return e.Err
+}
+
+// A row which failed to parse, quarantined rather than failing its whole table
+type RowReject struct {
+	Row    int // 1-based, counting data rows only
+	Column string
+	Cells  []string
+	Err    string
+}
+
+// Decides how many rows a table may reject before parsing gives up on it.
+// A negative MaxRows places no limit on the count, and a MaxPercent of zero or
+// less places no limit on the share of rows. The zero value tolerates no rejects.
+type ErrorBudget struct {
+	MaxRows    int
+	MaxPercent float64
+}
+
+// Controls how a single table is read
+type TableOptions struct {
+	Budget ErrorBudget
+	Drift  DriftPolicy
+}
+
+// Diagnostics gathered while reading a single table
+type TableReport struct {
+	Header  []string
+	Drift   HeaderDrift
+	Rejects []RowReject
+}
+
+// Parses the data rows of one table into records, quarantining rows which fail
+// to parse for as long as the error budget allows.
+type tableReader[T any] struct {
+	binder *RowBinder[T]
+	opts   TableOptions
+
+	rowNum  int
+	records []*T
+	report  TableReport
+}
+
+// Creates a reader for a table with the given header, failing if the header has
+// drifted from T further than the drift policy allows.
+func newTableReader[T any](header []string, binding *Binding[T], opts TableOptions) (*tableReader[T], error) {
+	t := &tableReader[T]{
+		opts: opts,
+		report: TableReport{
+			Header: header,
+			Drift:  compareColumns(header, binding.columns),
+		},
+	}
+	if err := opts.Drift.check(t.report.Drift); err != nil {
+		return t, err
+	}
+
+	binder, err := binding.Bind(header)
+	if err != nil {
+		return t, err
+	}
+	t.binder = binder
+	return t, nil
+}
+
+func (t *tableReader[T]) add(row []string) error {
+	t.rowNum++
+
+	res, err := t.binder.Parse(row)
+	if err == nil && res == nil {
+		err = errors.New("nul result")
+	}
+	if err == nil {
+		t.records = append(t.records, res)
+		return nil
+	}
+
+	reject := RowReject{Row: t.rowNum, Cells: row, Err: err.Error()}
+	var cellErr *CellError
+	if errors.As(err, &cellErr) {
+		reject.Column = cellErr.Column
+	}
+	t.report.Rejects = append(t.report.Rejects, reject)
+
+	if budget := t.opts.Budget; budget.MaxRows >= 0 && len(t.report.Rejects) > budget.MaxRows {
+		return fmt.Errorf("row %d: %w: %w", t.rowNum, ErrBudgetExceeded, err)
+	}
+	return nil
+}
+
+// Checks the share of rejected rows once every row has been read
+func (t *tableReader[T]) finish() error {
+	budget := t.opts.Budget
+	if budget.MaxPercent <= 0 || t.rowNum == 0 {
+		return nil
+	}
+
+	percent := float64(len(t.report.Rejects)) / float64(t.rowNum) * 100
+	if percent > budget.MaxPercent {
+		return fmt.Errorf("%w: %d of %d rows rejected (%.2f%%, limit %.2f%%)",
+			ErrBudgetExceeded, len(t.report.Rejects), t.rowNum, percent, budget.MaxPercent)
+	}
+	return nil
+}
+
+// Writes rejected rows to CSV: the row number, failing column and parser error,
+// followed by the row's raw cells under the table's own header.
+func WriteRejects(fileName string, header []string, rejects []RowReject) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write(append([]string{"row_number", "column", "error"}, header...)); err != nil {
+		return err
+	}
+
+	for _, reject := range rejects {
+		record := append([]string{strconv.Itoa(reject.Row), reject.Column, reject.Err}, reject.Cells...)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package helpers
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+type budgetRecord struct {
+	Kills int64 `col:"Kills"`
+}
+
+var budgetBinding = NewBinding[budgetRecord](ColumnTag, map[string]FieldParser{"Kills": IntParser()})
+
+// Reads a table of the given number of rows, the last bad of which fail to parse,
+// returning the rows rejected and the error which ended it
+func readBudgetTable(t *testing.T, rows, bad int, budget ErrorBudget) ([]RowReject, error) {
+	t.Helper()
+	reader, err := newTableReader([]string{"Kills"}, budgetBinding, TableOptions{Budget: budget})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range rows {
+		cell := strconv.Itoa(i)
+		if i >= rows-bad {
+			cell = "lots"
+		}
+		if err := reader.add([]string{cell}); err != nil {
+			return reader.report.Rejects, err
+		}
+	}
+	return reader.report.Rejects, reader.finish()
+}
+
+// Each limit passes a table which reaches it and fails one which crosses it by a row
+func TestErrorBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		budget  ErrorBudget
+		rows    int
+		bad     int
+		wantErr bool
+	}{
+		{name: "zero value without rejects", rows: 10},
+		{name: "zero value with one reject", rows: 10, bad: 1, wantErr: true},
+		{name: "count reached", budget: ErrorBudget{MaxRows: 3}, rows: 10, bad: 3},
+		{name: "count crossed", budget: ErrorBudget{MaxRows: 3}, rows: 10, bad: 4, wantErr: true},
+		{name: "no count limit", budget: ErrorBudget{MaxRows: -1}, rows: 10, bad: 10},
+		{name: "percent reached", budget: ErrorBudget{MaxRows: -1, MaxPercent: 20}, rows: 10, bad: 2},
+		{name: "percent crossed", budget: ErrorBudget{MaxRows: -1, MaxPercent: 20}, rows: 10, bad: 3, wantErr: true},
+		{name: "count crossed within percent", budget: ErrorBudget{MaxRows: 1, MaxPercent: 50}, rows: 10, bad: 2, wantErr: true},
+		{name: "percent crossed within count", budget: ErrorBudget{MaxRows: 5, MaxPercent: 10}, rows: 10, bad: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rejects, err := readBudgetTable(t, tt.rows, tt.bad, tt.budget)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrBudgetExceeded) {
+				t.Errorf("error %v, want ErrBudgetExceeded", err)
+			}
+			if err == nil && len(rejects) != tt.bad {
+				t.Errorf("%d rejects, want %d", len(rejects), tt.bad)
+			}
+		})
+	}
+}
+
+// A count limit stops at the first row over it; the rejects up to it are reported
+func TestErrorBudgetStopsAtRow(t *testing.T) {
+	rejects, err := readBudgetTable(t, 10, 4, ErrorBudget{MaxRows: 2})
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("error %v, want ErrBudgetExceeded", err)
+	}
+	if len(rejects) != 3 || rejects[2].Row != 9 {
+		t.Fatalf("rejects %+v, want 3 ending at row 9", rejects)
+	}
+	if rejects[0].Row != 7 || rejects[0].Column != "Kills" || rejects[0].Cells[0] != "lots" {
+		t.Errorf("first reject %+v, want row 7 of Kills", rejects[0])
+	}
+}
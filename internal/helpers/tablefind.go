@@ -8,115 +8,184 @@ import (
 	"github.com/PuerkitoBio/goquery"
 )
 
-// Finds and returns an H1 element, identified by its text.
-// *not* case-sensitive
-func findH1Element(doc *goquery.Document, headerText string) (*goquery.Selection, error) {
-	var result *goquery.Selection
-	doc.Find("h1").EachWithBreak(func(i int, s *goquery.Selection) bool {
-		actualHeader := strings.TrimSpace(s.Text())
-		if strings.EqualFold(actualHeader, strings.TrimSpace(headerText)) {
-			result = s
-			return false // stop iteration
-		}
-		return true // keep searching
-	})
+// Returned, wrapped, when an export does not contain the section holding a table
+var ErrSectionNotFound = errors.New("section not found")
 
-	if result == nil {
-		return nil, fmt.Errorf("failed to find H1 element with text %s", headerText)
-	} else {
-		return result, nil
-	}
+// Returned, wrapped, when a table is present but holds no data rows
+var ErrNoRows = errors.New("no rows found")
+
+// Reports whether err means the table is absent or empty, rather than malformed
+func IsMissingSection(err error) bool {
+	return errors.Is(err, ErrSectionNotFound) || errors.Is(err, ErrNoRows)
 }
 
-// Finds and returns an H2 element, identified by its text.
-// *not* case-sensitive
-func findH2Element(h1 *goquery.Selection, headerText string) (*goquery.Selection, error) {
-	sibling := h1.Next()
+// Reports whether a heading's text matches the expected title, ignoring case and surrounding whitespace
+func HeadingMatches(actual, expected string) bool {
+	return strings.EqualFold(strings.TrimSpace(actual), strings.TrimSpace(expected))
+}
 
-	for sibling.Length() > 0 {
-		if goquery.NodeName(sibling) == "h2" {
-			actualHeader := strings.TrimSpace(sibling.Text())
-			if strings.EqualFold(actualHeader, strings.TrimSpace(headerText)) {
-				return sibling, nil
+// Finds the top-level tables within the section of the first matching H1 and H2,
+// i.e. after both headings but before the next of either. Large sections are
+// paginated into several tables, so the selection may hold more than one.
+// Headings and tables are taken in document order, so the section may be wrapped
+// in other elements.
+func FindTableAfterHeaders(doc *goquery.Document, loc *Locator) (*goquery.Selection, error) {
+	var (
+		h1, h2 string
+		result *goquery.Selection
+	)
+	doc.Find("h1, h2, table").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		switch goquery.NodeName(s) {
+		case "h1":
+			h1, h2 = strings.TrimSpace(s.Text()), ""
+		case "h2":
+			h2 = strings.TrimSpace(s.Text())
+		case "table":
+			if isNestedTable(s) {
+				return true
+			}
+			if result != nil {
+				result = result.AddSelection(s)
+				return true
+			}
+			if loc.Matches(h1, h2) {
+				result = s
 			}
+			return true
 		}
-		sibling = sibling.Next()
+		// a heading ends the matching section
+		return result == nil
+	})
+
+	if result == nil {
+		return nil, fmt.Errorf("failed to find table under H1 %s, H2 %s: %w", &loc.H1, &loc.H2, ErrSectionNotFound)
 	}
+	return result, nil
+}
 
-	return nil, fmt.Errorf("failed to find H2 element with text %s", headerText)
+func isNestedTable(table *goquery.Selection) bool {
+	return table.ParentsFiltered("table").Length() > 0
 }
 
-// Finds the first table element following a given H2 element.
-func findTableAfterH2(h2 *goquery.Selection) (*goquery.Selection, error) {
-	sibling := h2.Next()
+// Reads the header and data rows of the table elements of one section, e.g. as
+// found by ListTables, concatenating the rows of each. Every table must have the
+// same header. See tableBuilder for how thead, tbody and tfoot, spanning cells and
+// row headers are handled; nested tables are ignored.
+func ReadTable(tables *goquery.Selection) ([]string, [][]string, error) {
+	if tables.Length() == 0 {
+		return nil, nil, errors.New("no table to read")
+	}
 
-	for sibling.Length() > 0 {
-		if goquery.NodeName(sibling) == "table" {
-			return sibling, nil
+	var (
+		header []string
+		rows   [][]string
+	)
+	for i, node := range tables.Nodes {
+		h, r, err := readTableNode(node)
+		if err != nil {
+			return nil, nil, fmt.Errorf("table %d of section: %w", i+1, err)
+		}
+		if i == 0 {
+			header = h
+		} else if err := checkContinuedHeader(i+1, h, header); err != nil {
+			return nil, nil, err
 		}
-		sibling = sibling.Next()
+		rows = append(rows, r...)
 	}
-
-	return nil, errors.New("failed to find table following H2 element")
+	return header, rows, nil
 }
 
-func FindTableAfterHeaders(doc *goquery.Document, h1Text, h2Text string) (*goquery.Selection, error) {
-	// Step 1: find the H1
-	h1, err := findH1Element(doc, h1Text)
-	if err != nil {
-		return nil, err
-	}
-
-	// Step 2: find the H2
-	h2, err := findH2Element(h1, h2Text)
-	if err != nil {
-		return nil, err
+// Checks that a later table of a paginated section has the header of the first
+func checkContinuedHeader(n int, header, first []string) error {
+	if err := compareHeader(header, first); err != nil {
+		return fmt.Errorf("table %d of section has a different header to the first: %v", n, err)
 	}
+	return nil
+}
 
-	// Step 3: find the Table
-	table, err := findTableAfterH2(h2)
+func FindTable(doc *goquery.Document, loc *Locator) ([]string, [][]string, error) {
+	table, err := FindTableAfterHeaders(doc, loc)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return table, nil
+	return ReadTable(table)
 }
 
-// parseTable takes a table element and returns its header and data rows.
-// Assumes the first <tr> contains <th> elements and all subsequent <tr> contain <td> data rows.
-func parseTable(table *goquery.Selection) ([]string, [][]string, error) {
-	var headers []string
-	var rows [][]string
+// Describes a table found in an export, along with the headings it falls under.
+type TableShape struct {
+	H1      string
+	H2      string
+	Header  []string
+	Rows    int
+	Columns int
+	Table   *goquery.Selection // every table of the section; nil for an H2 section holding none
+}
 
-	// Extract headers
-	headerRow := table.Find("tr").First()
-	headerRow.Find("th").Each(func(_ int, th *goquery.Selection) {
-		headers = append(headers, strings.TrimSpace(th.Text()))
-	})
+// Walks the document in order, returning the shape of the top-level tables of every
+// section and the H1/H2 headings they follow; a section paginated into several
+// tables has one shape. H2 sections holding no table are included with an empty
+// shape, so that no section goes unlisted.
+func ListTables(doc *goquery.Document) []TableShape {
+	var (
+		shapes       []TableShape
+		h1, h2       string
+		h2HasTable   bool
+		h2Registered bool
+		section      *goquery.Selection // the tables of the current section
+	)
+
+	flushSection := func() {
+		if section != nil {
+			shapes = append(shapes, measureTable(h1, h2, section))
+			section = nil
+		}
+	}
 
-	if len(headers) == 0 {
-		return nil, nil, errors.New("no headers found in table")
+	flushEmptyH2 := func() {
+		if h2Registered && !h2HasTable {
+			shapes = append(shapes, TableShape{H1: h1, H2: h2})
+		}
 	}
 
-	// Extract data rows (skip header row)
-	table.Find("tr").NextAll().Each(func(_ int, tr *goquery.Selection) {
-		var row []string
-		tr.Find("td").Each(func(_ int, td *goquery.Selection) {
-			row = append(row, strings.TrimSpace(td.Text()))
-		})
-		if len(row) > 0 {
-			rows = append(rows, row)
+	doc.Find("h1, h2, table").Each(func(_ int, s *goquery.Selection) {
+		switch goquery.NodeName(s) {
+		case "h1":
+			flushSection()
+			flushEmptyH2()
+			h1, h2 = strings.TrimSpace(s.Text()), ""
+			h2Registered, h2HasTable = false, false
+		case "h2":
+			flushSection()
+			flushEmptyH2()
+			h2 = strings.TrimSpace(s.Text())
+			h2Registered, h2HasTable = true, false
+		case "table":
+			if isNestedTable(s) {
+				return
+			}
+			h2HasTable = true
+			if section == nil {
+				section = s
+			} else {
+				section = section.AddSelection(s)
+			}
 		}
 	})
+	flushSection()
+	flushEmptyH2()
 
-	return headers, rows, nil
+	return shapes
 }
 
-func FindTable(doc *goquery.Document, h1Text, h2Text string) ([]string, [][]string, error) {
-	table, err := FindTableAfterHeaders(doc, h1Text, h2Text)
+// Counts the header cells and data rows of a table, as read by ReadTable.
+func measureTable(h1, h2 string, table *goquery.Selection) TableShape {
+	shape := TableShape{H1: h1, H2: h2, Table: table}
+
+	header, rows, err := ReadTable(table)
 	if err != nil {
-		return nil, nil, err
+		return shape
 	}
-
-	return parseTable(table)
+	shape.Header, shape.Columns, shape.Rows = header, len(header), len(rows)
+	return shape
 }
@@ -0,0 +1,191 @@
+package helpers
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// An export of several sections, each table's one data cell naming it
+const sectionsExport = `
+<h1>Game A</h1>
+<h2>Match Data</h2>
+<table><tr><th>T</th></tr><tr><td>a-match</td></tr></table>
+<h2>Zombies Data</h2>
+<p>none</p>
+<h1>Game B</h1>
+<table><tr><th>T</th></tr><tr><td>b-untitled</td></tr></table>
+<div><section>
+	<h2>Match Data</h2>
+	<div><table><tr><th>T</th></tr><tr><td>b-match<table><tr><th>T</th></tr><tr><td>nested</td></tr></table></td></tr></table></div>
+</section></div>
+<h2>Campaign Data</h2>
+<table><tr><th>T</th></tr><tr><td>b-campaign</td></tr></table>
+`
+
+func TestFindTable(t *testing.T) {
+	doc := mustDocument(t, sectionsExport)
+	tests := []struct {
+		name string
+		loc  *Locator
+		want string // the data cell of the table found, or empty for none
+	}{
+		{name: "first section", loc: NewLocator("Game A", "Match Data"), want: "a-match"},
+		{name: "same H2 under a later H1", loc: NewLocator("Game B", "Match Data"), want: "b-match"},
+		{name: "heading case and space", loc: NewLocator(" game b ", "CAMPAIGN DATA"), want: "b-campaign"},
+		{name: "H2 without a table stops at the next H1", loc: NewLocator("Game A", "Zombies Data")},
+		{name: "H2 of another H1", loc: NewLocator("Game A", "Campaign Data")},
+		{name: "absent H1", loc: NewLocator("Game C", "Match Data")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, rows, err := FindTable(doc, tt.loc)
+			if tt.want == "" {
+				if !errors.Is(err, ErrSectionNotFound) {
+					t.Fatalf("rows %q, error %v, want ErrSectionNotFound", rows, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := [][]string{{tt.want}}; !reflect.DeepEqual(rows, want) {
+				t.Errorf("rows %q, want %q", rows, want)
+			}
+		})
+	}
+}
+
+func TestListTables(t *testing.T) {
+	type shape struct {
+		H1, H2 string
+		Rows   int
+		Table  bool
+	}
+	want := []shape{
+		{H1: "Game A", H2: "Match Data", Rows: 1, Table: true},
+		{H1: "Game A", H2: "Zombies Data"},
+		{H1: "Game B", Rows: 1, Table: true},
+		{H1: "Game B", H2: "Match Data", Rows: 1, Table: true},
+		{H1: "Game B", H2: "Campaign Data", Rows: 1, Table: true},
+	}
+
+	var got []shape
+	for _, s := range ListTables(mustDocument(t, sectionsExport)) {
+		got = append(got, shape{H1: s.H1, H2: s.H2, Rows: s.Rows, Table: s.Table != nil})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListTables = %+v\nwant %+v", got, want)
+	}
+}
+
+// A section paginated into three tables is read as one, whether from the DOM or
+// streamed, and fails if any later table's header differs from the first
+func TestPaginatedSection(t *testing.T) {
+	page2Header := "<p>Page 2</p>\n<table>\n  <tr><th>UTC Timestamp</th><th>Map</th></tr>"
+	page3Header := "<th>UTC&nbsp;Timestamp</th><th>Map</th>"
+
+	tests := []struct {
+		name    string
+		old     string // replaced in the fixture with new
+		new     string
+		wantErr string
+	}{
+		{name: "concatenated"},
+		{
+			name:    "second table with another column",
+			old:     page2Header,
+			new:     strings.Replace(page2Header, "</tr>", "<th>Mode</th></tr>", 1),
+			wantErr: `table 2 of section has a different header to the first: 3 columns, expected 2`,
+		},
+		{
+			name:    "third table missing a column",
+			old:     page3Header,
+			new:     "<th>UTC Timestamp</th>",
+			wantErr: `table 3 of section has a different header to the first: 1 columns, expected 2`,
+		},
+		{
+			name:    "third table reordered",
+			old:     page3Header,
+			new:     "<th>Map</th><th>UTC Timestamp</th>",
+			wantErr: "table 3 of section has a different header to the first",
+		},
+	}
+	wantHeader := []string{"UTC Timestamp", "Map"}
+	wantRows := [][]string{
+		{"2024-03-01 20:00:00", "Rust"},
+		{"2024-03-01 19:00:00", "Shipment"},
+		{"2024-03-01 18:00:00", "Nuketown"},
+		{"2024-03-01 17:00:00", "Terminal"},
+		{"2024-03-01 16:00:00", "Rust"},
+	}
+	loc := NewLocator("Test Game", "Match Data").WithH2Patterns(QualifiedTitlePattern("Match Data"))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export := string(readFixture(t, "paginated.html"))
+			if tt.old != "" {
+				if !strings.Contains(export, tt.old) {
+					t.Fatalf("fixture does not contain %q", tt.old)
+				}
+				export = strings.Replace(export, tt.old, tt.new, 1)
+			}
+
+			domHeader, domRows, domErr := FindTable(mustDocument(t, export), loc)
+
+			sink := &recordingSink{TableSink: NewTableCollector(loc, NewBinding[struct{}](ColumnTag, nil), TableOptions{})}
+			if err := StreamTables(strings.NewReader(export), sink); err != nil {
+				t.Fatal(err)
+			}
+
+			for reader, got := range map[string]struct {
+				header []string
+				rows   [][]string
+				err    error
+			}{
+				"DOM":    {domHeader, domRows, domErr},
+				"stream": {sink.header, sink.rows, sink.err},
+			} {
+				if tt.wantErr != "" {
+					if got.err == nil || !strings.Contains(got.err.Error(), tt.wantErr) {
+						t.Errorf("%s error %v, want %q", reader, got.err, tt.wantErr)
+					}
+					continue
+				}
+				if got.err != nil {
+					t.Fatalf("%s: %v", reader, got.err)
+				}
+				if !reflect.DeepEqual(got.header, wantHeader) {
+					t.Errorf("%s header %q, want %q", reader, got.header, wantHeader)
+				}
+				if !reflect.DeepEqual(got.rows, wantRows) {
+					t.Errorf("%s rows %q\nwant %q", reader, got.rows, wantRows)
+				}
+			}
+		})
+	}
+}
+
+// Records what a sink is sent while streaming
+type recordingSink struct {
+	TableSink
+	header []string
+	rows   [][]string
+	err    error
+}
+
+func (s *recordingSink) Header(header []string) error {
+	s.header = header
+	return s.TableSink.Header(header)
+}
+
+func (s *recordingSink) Row(row []string) error {
+	s.rows = append(s.rows, row)
+	return s.TableSink.Row(row)
+}
+
+func (s *recordingSink) Fail(err error) {
+	s.err = err
+	s.TableSink.Fail(err)
+}
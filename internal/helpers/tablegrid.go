@@ -0,0 +1,312 @@
+package helpers
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// The largest colspan and rowspan honoured, as in the HTML specification
+const (
+	maxColspan = 1000
+	maxRowspan = 65534
+)
+
+// A cell spanning down from an earlier row, and how many more rows it covers
+type pendingSpan struct {
+	text string
+	rows int
+}
+
+// Assembles the header and data rows of a table from its cells in document order,
+// shared by the DOM and streaming readers. Cells spanning several columns or rows
+// are repeated into each position they cover.
+//
+// The header is the rows of the <thead>, or failing one, the leading rows made up
+// only of <th> cells; a header of several rows joins the labels of each column.
+// Every later row is data, whether its cells are <th> or <td>, except the rows of
+// a <tfoot>, which hold totals rather than records, and rows repeating the header.
+type tableBuilder struct {
+	onHeader func(header []string) error
+	onRow    func(row []string) error
+
+	section    string // "thead", "tbody" or "tfoot" while inside one
+	sawHead    bool
+	headerRows [][]string
+	headerDone bool
+
+	inRow bool
+	row   []string
+	allTh bool
+	col   int
+	spans []pendingSpan // by column
+}
+
+func newTableBuilder(onHeader func([]string) error, onRow func([]string) error) *tableBuilder {
+	return &tableBuilder{onHeader: onHeader, onRow: onRow}
+}
+
+// Enters a row group; cells spanning rows do not extend past the end of their group.
+func (b *tableBuilder) startSection(name string) error {
+	if err := b.endRow(); err != nil {
+		return err
+	}
+	b.section, b.spans = name, nil
+	if name == "thead" {
+		b.sawHead = true
+	}
+	return nil
+}
+
+func (b *tableBuilder) endSection() error {
+	if err := b.endRow(); err != nil {
+		return err
+	}
+	b.section, b.spans = "", nil
+	return nil
+}
+
+func (b *tableBuilder) startRow() error {
+	if err := b.endRow(); err != nil {
+		return err
+	}
+	b.inRow, b.row, b.allTh, b.col = true, nil, true, 0
+	return nil
+}
+
+// Places a cell in the current row after any cells spanning down into it from above.
+func (b *tableBuilder) addCell(kind, text string, colspan, rowspan int) error {
+	if !b.inRow {
+		if err := b.startRow(); err != nil {
+			return err
+		}
+	}
+	b.fillSpans()
+
+	if kind != "th" {
+		b.allTh = false
+	}
+	text = normalizeCellText(text)
+	for range colspan {
+		b.row = append(b.row, text)
+		if rowspan != 1 {
+			for len(b.spans) <= b.col {
+				b.spans = append(b.spans, pendingSpan{})
+			}
+			rows := rowspan - 1
+			if rowspan == 0 {
+				rows = maxRowspan // to the end of the row group
+			}
+			b.spans[b.col] = pendingSpan{text: text, rows: rows}
+		}
+		b.col++
+	}
+	return nil
+}
+
+// Fills the columns at the current position held by cells spanning down from above
+func (b *tableBuilder) fillSpans() {
+	for b.col < len(b.spans) && b.spans[b.col].rows > 0 {
+		b.row = append(b.row, b.spans[b.col].text)
+		b.spans[b.col].rows--
+		b.col++
+	}
+}
+
+// Completes the current row, if any, handing it to the header or data rows.
+func (b *tableBuilder) endRow() error {
+	if !b.inRow {
+		return nil
+	}
+	b.inRow = false
+
+	// cells spanning down from above which no cell of this row preceded
+	for ; b.col < len(b.spans); b.col++ {
+		if b.spans[b.col].rows == 0 {
+			continue
+		}
+		for len(b.row) < b.col {
+			b.row = append(b.row, "")
+		}
+		b.row = append(b.row, b.spans[b.col].text)
+		b.spans[b.col].rows--
+	}
+
+	if len(b.row) == 0 {
+		return nil
+	}
+
+	switch {
+	case b.section == "tfoot":
+		return nil
+	case b.section == "thead", !b.headerDone && !b.sawHead && b.allTh:
+		if !b.headerDone {
+			b.headerRows = append(b.headerRows, b.row)
+		}
+		return nil
+	}
+
+	if err := b.emitHeader(); err != nil {
+		return err
+	}
+	if b.repeatsHeader(b.row) {
+		return nil
+	}
+	return b.onRow(b.row)
+}
+
+// Reports whether a body row repeats a header row, as paginated exports do
+func (b *tableBuilder) repeatsHeader(row []string) bool {
+	for _, header := range b.headerRows {
+		if slices.Equal(row, header) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *tableBuilder) emitHeader() error {
+	if b.headerDone {
+		return nil
+	}
+	b.headerDone = true
+	if len(b.headerRows) == 0 {
+		return errors.New("no headers found in table")
+	}
+	return b.onHeader(joinHeaderRows(b.headerRows))
+}
+
+// Completes the table, emitting its header if no data row has done so.
+func (b *tableBuilder) finish() error {
+	if err := b.endRow(); err != nil {
+		return err
+	}
+	return b.emitHeader()
+}
+
+// Joins the distinct labels of each column of a multi-row header, top to bottom,
+// e.g. "Kills" above "Total" becomes "Kills Total".
+func joinHeaderRows(rows [][]string) []string {
+	if len(rows) == 1 {
+		return rows[0]
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	header := make([]string, width)
+	for i := range header {
+		var labels []string
+		for _, row := range rows {
+			if i < len(row) && row[i] != "" && (len(labels) == 0 || labels[len(labels)-1] != row[i]) {
+				labels = append(labels, row[i])
+			}
+		}
+		header[i] = strings.Join(labels, " ")
+	}
+	return header
+}
+
+// Collapses runs of whitespace, including non-breaking spaces, to a single space
+// and trims the ends. Entities have already been decoded by the HTML tokenizer.
+func normalizeCellText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Reads a colspan or rowspan attribute, which defaults to 1 when absent or invalid.
+// A rowspan of 0 extends to the end of the row group.
+func parseSpan(val string, allowZero bool, limit int) int {
+	n, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil || n < 0 || (n == 0 && !allowZero) {
+		return 1
+	}
+	return min(n, limit)
+}
+
+// Reads the rows of a parsed table element, ignoring any tables nested within it.
+func readTableNode(table *html.Node) ([]string, [][]string, error) {
+	var (
+		header []string
+		rows   [][]string
+	)
+	b := newTableBuilder(
+		func(h []string) error { header = h; return nil },
+		func(row []string) error { rows = append(rows, row); return nil },
+	)
+
+	var walk func(n *html.Node) error
+	walk = func(n *html.Node) error {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+
+			var err error
+			switch c.Data {
+			case "thead", "tbody", "tfoot":
+				if err = b.startSection(c.Data); err == nil {
+					if err = walk(c); err == nil {
+						err = b.endSection()
+					}
+				}
+			case "tr":
+				if err = b.startRow(); err == nil {
+					if err = walk(c); err == nil {
+						err = b.endRow()
+					}
+				}
+			case "th", "td":
+				colspan := parseSpan(nodeAttr(c, "colspan"), false, maxColspan)
+				rowspan := parseSpan(nodeAttr(c, "rowspan"), true, maxRowspan)
+				err = b.addCell(c.Data, nodeText(c), colspan, rowspan)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(table); err != nil {
+		return nil, nil, err
+	}
+	if err := b.finish(); err != nil {
+		return nil, nil, err
+	}
+	return header, rows, nil
+}
+
+func nodeAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// Returns the text within a cell, treating line breaks as spaces and ignoring
+// nested tables.
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch {
+			case c.Type == html.TextNode:
+				sb.WriteString(c.Data)
+			case c.Type == html.ElementNode && c.Data == "br":
+				sb.WriteByte(' ')
+			case c.Type == html.ElementNode && c.Data != "table":
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return sb.String()
+}
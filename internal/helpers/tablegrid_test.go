@@ -0,0 +1,176 @@
+package helpers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Reads a table both from the DOM and while streaming, failing the test unless
+// they agree
+func readBothWays(t *testing.T, table string) ([]string, [][]string, error) {
+	t.Helper()
+	doc := mustDocument(t, "<h1>G</h1><h2>S</h2>"+table)
+	header, rows, err := FindTable(doc, NewLocator("G", "S"))
+
+	var collector GenericCollector
+	if err := StreamTables(strings.NewReader(table), &collector); err != nil {
+		t.Fatal(err)
+	}
+	streamed, streamErr := collector.Result()
+	switch {
+	case (err == nil) != (streamErr == nil):
+		t.Fatalf("DOM error %v, stream error %v", err, streamErr)
+	case err == nil && len(streamed) != 1:
+		t.Fatalf("streamed %d tables, want 1", len(streamed))
+	case err == nil && (!reflect.DeepEqual(streamed[0].Header, header) || !reflect.DeepEqual(streamed[0].Rows, rows)):
+		t.Fatalf("DOM read %q %q, stream read %q %q", header, rows, streamed[0].Header, streamed[0].Rows)
+	}
+	return header, rows, err
+}
+
+func TestTableBuilder(t *testing.T) {
+	tests := []struct {
+		name   string
+		table  string
+		header []string
+		rows   [][]string
+	}{
+		{
+			name:   "plain",
+			table:  `<table><tr><th>A</th><th>B</th></tr><tr><td>1</td><td>2</td></tr></table>`,
+			header: []string{"A", "B"},
+			rows:   [][]string{{"1", "2"}},
+		},
+		{
+			name:   "colspan",
+			table:  `<table><tr><th>A</th><th>B</th><th>C</th></tr><tr><td colspan="2">1</td><td>2</td></tr></table>`,
+			header: []string{"A", "B", "C"},
+			rows:   [][]string{{"1", "1", "2"}},
+		},
+		{
+			name: "rowspan",
+			table: `<table><tr><th>A</th><th>B</th></tr>
+				<tr><td rowspan="3">x</td><td>1</td></tr><tr><td>2</td></tr><tr><td>3</td></tr><tr><td>y</td><td>4</td></tr></table>`,
+			header: []string{"A", "B"},
+			rows:   [][]string{{"x", "1"}, {"x", "2"}, {"x", "3"}, {"y", "4"}},
+		},
+		{
+			name: "rowspan in a middle column",
+			table: `<table><tr><th>A</th><th>B</th><th>C</th></tr>
+				<tr><td>1</td><td rowspan="2">x</td><td>2</td></tr><tr><td>3</td><td>4</td></tr></table>`,
+			header: []string{"A", "B", "C"},
+			rows:   [][]string{{"1", "x", "2"}, {"3", "x", "4"}},
+		},
+		{
+			name: "rowspan of zero ends with its row group",
+			table: `<table><thead><tr><th>A</th><th>B</th></tr></thead>
+				<tbody><tr><td rowspan="0">x</td><td>1</td></tr><tr><td>2</td></tr></tbody>
+				<tbody><tr><td>y</td><td>3</td></tr></tbody></table>`,
+			header: []string{"A", "B"},
+			rows:   [][]string{{"x", "1"}, {"x", "2"}, {"y", "3"}},
+		},
+		{
+			name:   "spans and invalid spans",
+			table:  `<table><tr><th colspan="2">A</th></tr><tr><td colspan="0">1</td><td colspan="x">2</td></tr></table>`,
+			header: []string{"A", "A"},
+			rows:   [][]string{{"1", "2"}},
+		},
+		{
+			name: "header of two rows",
+			table: `<table><thead><tr><th rowspan="2">Map</th><th colspan="2">Kills</th></tr>
+				<tr><th>Total</th><th>Best</th></tr></thead><tr><td>Rust</td><td>5</td><td>3</td></tr></table>`,
+			header: []string{"Map", "Kills Total", "Kills Best"},
+			rows:   [][]string{{"Rust", "5", "3"}},
+		},
+		{
+			name:   "row headers",
+			table:  `<table><tr><th>Map</th><th>Kills</th></tr><tr><th>Rust</th><td>5</td></tr><tr><th>Shipment</th><th>7</th></tr></table>`,
+			header: []string{"Map", "Kills"},
+			rows:   [][]string{{"Rust", "5"}, {"Shipment", "7"}},
+		},
+		{
+			name:   "thead of td cells",
+			table:  `<table><thead><tr><td>A</td><td>B</td></tr></thead><tbody><tr><th>1</th><th>2</th></tr></tbody></table>`,
+			header: []string{"A", "B"},
+			rows:   [][]string{{"1", "2"}},
+		},
+		{
+			name:   "tfoot",
+			table:  `<table><thead><tr><th>A</th></tr></thead><tfoot><tr><td>total</td></tr></tfoot><tbody><tr><td>1</td></tr></tbody></table>`,
+			header: []string{"A"},
+			rows:   [][]string{{"1"}},
+		},
+		{
+			name:   "repeated header rows",
+			table:  `<table><tr><th>A</th><th>B</th></tr><tr><td>1</td><td>2</td></tr><tr><th>A</th><th>B</th></tr><tr><td>3</td><td>4</td></tr></table>`,
+			header: []string{"A", "B"},
+			rows:   [][]string{{"1", "2"}, {"3", "4"}},
+		},
+		{
+			name:   "whitespace and non-breaking spaces",
+			table:  "<table><tr><th> Time&nbsp;&nbsp;Played </th><th>\n\tMap\n</th></tr><tr><td>1&nbsp;:&#160;00</td><td>Rust<br>Yard</td></tr></table>",
+			header: []string{"Time Played", "Map"},
+			rows:   [][]string{{"1 : 00", "Rust Yard"}},
+		},
+		{
+			name: "nested tables",
+			table: `<table><tr><th>A</th><th>B</th></tr>
+				<tr><td>1<table><tr><th>X</th></tr><tr><td>nested</td></tr></table></td><td>2</td></tr></table>`,
+			header: []string{"A", "B"},
+			rows:   [][]string{{"1", "2"}},
+		},
+		{
+			name:   "header only",
+			table:  `<table><tr><th>A</th><th>B</th></tr></table>`,
+			header: []string{"A", "B"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, rows, err := readBothWays(t, tt.table)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(header, tt.header) {
+				t.Errorf("header %q, want %q", header, tt.header)
+			}
+			if !reflect.DeepEqual(rows, tt.rows) {
+				t.Errorf("rows %q, want %q", rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestTableBuilderWithoutHeader(t *testing.T) {
+	for _, table := range []string{
+		`<table><tr><td>1</td><td>2</td></tr></table>`,
+		`<table></table>`,
+	} {
+		if _, _, err := readBothWays(t, table); err == nil {
+			t.Errorf("read %s without error, want no headers found", table)
+		}
+	}
+}
+
+func TestParseSpan(t *testing.T) {
+	tests := []struct {
+		val       string
+		allowZero bool
+		want      int
+	}{
+		{"", false, 1},
+		{"3", false, 3},
+		{" 2 ", false, 2},
+		{"0", false, 1},
+		{"0", true, 0},
+		{"-1", true, 1},
+		{"two", true, 1},
+		{"5000", false, maxColspan},
+	}
+	for _, tt := range tests {
+		if got := parseSpan(tt.val, tt.allowZero, maxColspan); got != tt.want {
+			t.Errorf("parseSpan(%q, %v) = %d, want %d", tt.val, tt.allowZero, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Receives a single table's header and rows as they are streamed from an export.
+// A section paginated into several tables is received as one table.
+type TableSink interface {
+	// Reports whether the sink wants the first table under the given headings
+	Wants(h1, h2 string) bool
+	Header(header []string) error
+	Row(row []string) error
+
+	// Receives the error which ended reading the table early, e.g. a later table
+	// of the section with a different header; no more of the table is sent
+	Fail(err error)
+}
+
+// Collects the records of one table while streaming, mirroring FromHtmlTable: a
+// table which fails, e.g. by exceeding its error budget, does not stop the stream,
+// and Result returns what FromHtmlTable would for it.
+type TableCollector[T any] struct {
+	loc     *Locator
+	binding *Binding[T]
+	opts    TableOptions
+
+	found   bool
+	reader  *tableReader[T]
+	rows    int   // data rows received, whether or not parsed
+	err     error // the error which ended parsing the table
+	readErr error // the error which ended reading the table
+}
+
+func NewTableCollector[T any](
+	loc *Locator,
+	binding *Binding[T],
+	opts TableOptions,
+) *TableCollector[T] {
+	return &TableCollector[T]{loc: loc, binding: binding, opts: opts}
+}
+
+func (c *TableCollector[T]) Wants(h1, h2 string) bool {
+	if c.found || !c.loc.Matches(h1, h2) {
+		return false
+	}
+	c.found = true
+	return true
+}
+
+// Parsing errors are kept for Result rather than returned, so the stream goes on
+// to other tables
+func (c *TableCollector[T]) Header(header []string) error {
+	c.reader, c.err = newTableReader(header, c.binding, c.opts)
+	return nil
+}
+
+func (c *TableCollector[T]) Row(row []string) error {
+	c.rows++
+	if c.err == nil {
+		c.err = c.reader.add(row)
+	}
+	return nil
+}
+
+func (c *TableCollector[T]) Fail(err error) {
+	if c.readErr == nil {
+		c.readErr = err
+	}
+}
+
+// Returns the collected records, or an error if the table was absent, empty,
+// could not be read, or rejected more rows than its error budget allows.
+func (c *TableCollector[T]) Result() ([]*T, TableReport, error) {
+	switch {
+	case !c.found:
+		return nil, TableReport{}, fmt.Errorf("failed to find table under H1 %s, H2 %s: %w", &c.loc.H1, &c.loc.H2, ErrSectionNotFound)
+	case c.readErr != nil:
+		return nil, TableReport{}, c.readErr
+	case c.reader == nil || len(c.reader.report.Header) == 0:
+		return nil, TableReport{}, errors.New("header row not found")
+	case c.rows == 0:
+		return nil, TableReport{Header: c.reader.report.Header}, ErrNoRows
+	case c.err != nil:
+		return nil, c.reader.report, c.err
+	}
+	if err := c.reader.finish(); err != nil {
+		return nil, c.reader.report, err
+	}
+	return c.reader.records, c.reader.report, nil
+}
+
+// Collects every table offered to it as a GenericTable; placed after the table
+// collectors it receives only the tables no registered handler claimed.
+type GenericCollector struct {
+	tables []*GenericTable
+	err    error
+
+	h1, h2 string
+	header []string
+	rows   [][]string
+	open   bool
+}
+
+func (g *GenericCollector) Wants(h1, h2 string) bool {
+	g.finish()
+	g.h1, g.h2 = h1, h2
+	return true
+}
+
+func (g *GenericCollector) Header(header []string) error {
+	g.header, g.rows, g.open = header, nil, true
+	return nil
+}
+
+func (g *GenericCollector) Row(row []string) error {
+	g.rows = append(g.rows, row)
+	return nil
+}
+
+func (g *GenericCollector) Fail(err error) {
+	g.open = false
+	if g.err == nil {
+		g.err = fmt.Errorf("unrecognized table under H1 %s, H2 %s: %w", g.h1, g.h2, err)
+	}
+}
+
+func (g *GenericCollector) finish() {
+	if g.open {
+		g.tables = append(g.tables, NewGenericTable(g.h1, g.h2, g.header, g.rows))
+		g.open = false
+	}
+}
+
+// Returns the collected tables, in document order, or the error of the first
+// which could not be read
+func (g *GenericCollector) Result() ([]*GenericTable, error) {
+	g.finish()
+	if g.err != nil {
+		return nil, g.err
+	}
+	return g.tables, nil
+}
+
+// Streams the first table under the provided headings through the binding, without
+// building a DOM of the whole document.
+func FromHtmlStream[T any](
+	r io.Reader,
+	loc *Locator,
+	binding *Binding[T],
+	opts TableOptions,
+) ([]*T, TableReport, error) {
+	collector := NewTableCollector(loc, binding, opts)
+	if err := StreamTables(r, collector); err != nil {
+		return nil, TableReport{}, err
+	}
+	return collector.Result()
+}
+
+// Tokenizes an export in a single pass, tracking the current H1 and H2 headings
+// and handing the tables of each section to the first sink that wants them.
+// Each table's header and rows are assembled as ReadTable does.
+func StreamTables(r io.Reader, sinks ...TableSink) error {
+	z := html.NewTokenizer(r)
+
+	var (
+		h1, h2      string
+		heading     string // "h1" or "h2" while inside one
+		headingText strings.Builder
+		section     *sectionSink // receiving the current section's tables, if any
+	)
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return err
+			}
+			return nil
+
+		case html.TextToken:
+			if heading != "" {
+				headingText.Write(z.Text())
+			}
+
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			switch tag := string(name); tag {
+			case "h1", "h2":
+				heading = tag
+				headingText.Reset()
+			case "table":
+				if section == nil {
+					for _, s := range sinks {
+						if s.Wants(h1, h2) {
+							section = &sectionSink{sink: s}
+							break
+						}
+					}
+				}
+				if section == nil || section.failed {
+					if err := skipTable(z); err != nil {
+						return err
+					}
+					continue
+				}
+				section.tables++
+				if err := streamTable(z, section); err != nil {
+					return fmt.Errorf("table under H1 %s, H2 %s: %w", h1, h2, err)
+				}
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if tag := string(name); tag == heading {
+				section = nil
+				text := strings.TrimSpace(headingText.String())
+				if tag == "h1" {
+					h1, h2 = text, ""
+				} else {
+					h2 = text
+				}
+				heading = ""
+			}
+		}
+	}
+}
+
+// Passes the tables of one section to a sink as a single table, checking that
+// each repeats the header of the first.
+type sectionSink struct {
+	sink   TableSink
+	tables int // the tables of the section started so far
+	header []string
+	failed bool
+}
+
+// Returned to end the current table once its sink has been failed
+var errTableFailed = errors.New("table failed")
+
+func (s *sectionSink) Header(header []string) error {
+	if s.tables == 1 {
+		s.header = header
+		return s.sink.Header(header)
+	}
+	if err := checkContinuedHeader(s.tables, header, s.header); err != nil {
+		s.fail(err)
+		return errTableFailed
+	}
+	return nil
+}
+
+func (s *sectionSink) Row(row []string) error {
+	return s.sink.Row(row)
+}
+
+// Fails the sink, as ReadTable fails for a table it cannot read; the rest of the
+// section is skipped
+func (s *sectionSink) fail(err error) {
+	s.failed = true
+	s.sink.Fail(err)
+}
+
+// Fails the sink for an error reading the current table, unless it already has
+// been, naming the table as ReadTable does
+func (s *sectionSink) failTable(err error) {
+	if !errors.Is(err, errTableFailed) {
+		s.fail(fmt.Errorf("table %d of section: %w", s.tables, err))
+	}
+}
+
+// Consumes tokens up to and including the end of the current table.
+func skipTable(z *html.Tokenizer) error {
+	depth := 1
+	for depth > 0 {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return err
+			}
+			return nil
+		case html.StartTagToken:
+			if name, _ := z.TagName(); string(name) == "table" {
+				depth++
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "table" {
+				depth--
+			}
+		}
+	}
+	return nil
+}
+
+// Reads the rows of the current table, up to and including its end tag, into the
+// sink, assembling them as ReadTable does. Nested tables are skipped. A table which
+// cannot be read fails the sink, and the rest of it is skipped; only errors reading
+// the document are returned.
+func streamTable(z *html.Tokenizer, sink *sectionSink) error {
+	b := newTableBuilder(sink.Header, sink.Row)
+
+	var (
+		cellKind string // "th" or "td" while inside a cell
+		cellText strings.Builder
+		colspan  int
+		rowspan  int
+	)
+
+	closeCell := func() error {
+		if cellKind == "" {
+			return nil
+		}
+		kind := cellKind
+		cellKind = ""
+		return b.addCell(kind, cellText.String(), colspan, rowspan)
+	}
+	finish := func() {
+		err := closeCell()
+		if err == nil {
+			err = b.finish()
+		}
+		if err != nil {
+			sink.failTable(err)
+		}
+	}
+
+	for {
+		var err error
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return err
+			}
+			finish()
+			return nil
+
+		case html.TextToken:
+			if cellKind != "" {
+				cellText.Write(z.Text())
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch tag := string(name); tag {
+			case "table":
+				if err := skipTable(z); err != nil {
+					return err
+				}
+			case "br":
+				if cellKind != "" {
+					cellText.WriteByte(' ')
+				}
+			case "thead", "tbody", "tfoot":
+				if err = closeCell(); err == nil {
+					err = b.startSection(tag)
+				}
+			case "tr":
+				if err = closeCell(); err == nil {
+					err = b.startRow()
+				}
+			case "th", "td":
+				if err = closeCell(); err == nil {
+					cellKind = tag
+					cellText.Reset()
+					colspan, rowspan = spanAttrs(z, hasAttr)
+				}
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "th", "td":
+				err = closeCell()
+			case "tr":
+				if err = closeCell(); err == nil {
+					err = b.endRow()
+				}
+			case "thead", "tbody", "tfoot":
+				if err = closeCell(); err == nil {
+					err = b.endSection()
+				}
+			case "table":
+				finish()
+				return nil
+			}
+		}
+		if err != nil {
+			sink.failTable(err)
+			return skipTable(z)
+		}
+	}
+}
+
+// Reads the colspan and rowspan of the current cell's start tag
+func spanAttrs(z *html.Tokenizer, hasAttr bool) (colspan, rowspan int) {
+	colspan, rowspan = 1, 1
+	for hasAttr {
+		var key, val []byte
+		key, val, hasAttr = z.TagAttr()
+		switch string(key) {
+		case "colspan":
+			colspan = parseSpan(string(val), false, maxColspan)
+		case "rowspan":
+			rowspan = parseSpan(string(val), true, maxRowspan)
+		}
+	}
+	return colspan, rowspan
+}
@@ -0,0 +1,151 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+type testMatch struct {
+	Timestamp  int64          `col:"UTC Timestamp"`
+	Map        string         `col:"Map"`
+	Mode       string         `col:"Mode"`
+	Kills      *int64         `col:"Combat Kills"`
+	Accuracy   *float64       `col:"Combat Accuracy"`
+	TimePlayed *time.Duration `col:"Time Played"`
+}
+
+var testMatchBinding = NewBinding[testMatch](ColumnTag, map[string]FieldParser{
+	"UTC Timestamp":   TimestampToUnixMillisInt64(),
+	"Map":             StringParser(),
+	"Mode":            StringParser(),
+	"Combat Kills":    IntParser(),
+	"Combat Accuracy": FloatParser(),
+	"Time Played":     DurationParser(),
+})
+
+var testMatchLocator = NewLocator("Test Game", "Match Data").
+	WithH2Patterns(QualifiedTitlePattern("Match Data"))
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// The DOM and streaming readers return exactly the same records, reports and
+// errors, whatever the table's layout and the options
+func TestStreamMatchesDOM(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		loc     *Locator
+		opts    TableOptions
+		wantErr bool
+	}{
+		{name: "lenient", fixture: "matches.html", loc: testMatchLocator, opts: TableOptions{Budget: ErrorBudget{MaxRows: -1}}},
+		{name: "budget exceeded", fixture: "matches.html", loc: testMatchLocator, opts: TableOptions{}, wantErr: true},
+		{name: "percentage exceeded", fixture: "matches.html", loc: testMatchLocator, opts: TableOptions{Budget: ErrorBudget{MaxRows: -1, MaxPercent: 10}}, wantErr: true},
+		{name: "strict schema", fixture: "matches.html", loc: testMatchLocator, opts: TableOptions{Drift: DriftStrict}, wantErr: true},
+		{name: "missing section", fixture: "matches.html", loc: NewLocator("Test Game", "Zombies Data"), wantErr: true},
+		{name: "first section", fixture: "matches.html", loc: NewLocator("Other Game", "Match Data (reverse chronological)"), opts: TableOptions{Budget: ErrorBudget{MaxRows: -1}}},
+		{name: "no header", fixture: "headerless.html", loc: testMatchLocator, wantErr: true},
+		{name: "paginated", fixture: "paginated.html", loc: testMatchLocator, opts: TableOptions{Budget: ErrorBudget{MaxRows: -1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := readFixture(t, tt.fixture)
+			doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			domRecords, domReport, domErr := FromHtmlTable(doc, tt.loc, testMatchBinding, tt.opts)
+			streamRecords, streamReport, streamErr := FromHtmlStream(bytes.NewReader(data), tt.loc, testMatchBinding, tt.opts)
+
+			if (domErr != nil) != tt.wantErr {
+				t.Fatalf("DOM error = %v, want error %v", domErr, tt.wantErr)
+			}
+			if (domErr == nil) != (streamErr == nil) || domErr != nil && domErr.Error() != streamErr.Error() {
+				t.Errorf("DOM error %v, stream error %v", domErr, streamErr)
+			}
+			if !reflect.DeepEqual(domRecords, streamRecords) {
+				t.Errorf("DOM records %s\nstream records %s", describeMatches(domRecords), describeMatches(streamRecords))
+			}
+			if !reflect.DeepEqual(domReport, streamReport) {
+				t.Errorf("DOM report %+v\nstream report %+v", domReport, streamReport)
+			}
+		})
+	}
+}
+
+// The fixture's records as both readers should parse them, so that agreement on
+// the wrong records does not pass
+func TestMatchesFixture(t *testing.T) {
+	data := readFixture(t, "matches.html")
+	records, report, err := FromHtmlStream(bytes.NewReader(data), testMatchLocator, testMatchBinding, TableOptions{Budget: ErrorBudget{MaxRows: -1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ms := func(s string) int64 {
+		ts, err := time.Parse(time.DateTime, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts.UnixMilli()
+	}
+	i := func(v int64) *int64 { return &v }
+	f := func(v float64) *float64 { return &v }
+	d := func(v time.Duration) *time.Duration { return &v }
+
+	want := []*testMatch{
+		{Timestamp: ms("2024-03-01 20:15:00"), Map: "Rust Yard", Kills: i(1234), Accuracy: f(25.5), TimePlayed: d(time.Hour + 2*time.Minute + 3*time.Second)},
+		{Timestamp: ms("2024-03-01 19:00:00"), Map: "Rust Yard", Kills: i(12), Accuracy: f(0.5), TimePlayed: d(5*time.Minute + 30*time.Second)},
+		{Timestamp: ms("2024-03-01 18:00:00"), Map: "Shipment"},
+		{Timestamp: ms("2024-03-01 16:00:00"), Map: "Nuketown", Kills: i(7), Accuracy: f(1.5), TimePlayed: d(90 * time.Second)},
+		{Timestamp: ms("2024-02-29 23:59:59"), Map: "Terminal", Kills: i(3), Accuracy: f(40), TimePlayed: d(45 * time.Second)},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records %s\nwant %s", describeMatches(records), describeMatches(want))
+	}
+
+	wantHeader := []string{"UTC Timestamp", "Map", "Combat Kills", "Combat Accuracy", "Time Played", "Notes"}
+	if !reflect.DeepEqual(report.Header, wantHeader) {
+		t.Errorf("header %q, want %q", report.Header, wantHeader)
+	}
+	wantDrift := HeaderDrift{Unknown: []string{"Notes"}, Missing: []string{"Mode"}}
+	if !reflect.DeepEqual(report.Drift, wantDrift) {
+		t.Errorf("drift %+v, want %+v", report.Drift, wantDrift)
+	}
+	if len(report.Rejects) != 1 || report.Rejects[0].Row != 4 || report.Rejects[0].Column != "Combat Kills" {
+		t.Errorf("rejects %+v, want row 4 for Combat Kills", report.Rejects)
+	}
+}
+
+func describeMatches(matches []*testMatch) string {
+	var b strings.Builder
+	for _, m := range matches {
+		fmt.Fprintf(&b, "\n\t%s %q %q", time.UnixMilli(m.Timestamp).UTC().Format(time.DateTime), m.Map, m.Mode)
+		if m.Kills != nil {
+			fmt.Fprintf(&b, " kills=%d", *m.Kills)
+		}
+		if m.Accuracy != nil {
+			fmt.Fprintf(&b, " accuracy=%v", *m.Accuracy)
+		}
+		if m.TimePlayed != nil {
+			fmt.Fprintf(&b, " played=%v", *m.TimePlayed)
+		}
+	}
+	return b.String()
+}
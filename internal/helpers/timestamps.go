@@ -0,0 +1,199 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// The layouts timestamps are parsed with unless WithLayouts is given: the export's
+// own "2006-01-02 15:04:05", its "T" separated form, and either with an offset.
+// Fractional seconds are accepted after the seconds in every layout.
+var DefaultTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02 15:04:05 Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02T15:04:05-0700",
+}
+
+// The layout timestamps are written to CSV with: RFC3339, with milliseconds when
+// the timestamp has them
+const TimestampLayout = "2006-01-02T15:04:05.999Z07:00"
+
+// Formats a millisecond timestamp for CSV in TimestampLayout
+func FormatUnixMillis(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format(TimestampLayout)
+}
+
+// Returned, wrapped, when a timestamp could denote more than one instant, or none
+var ErrAmbiguousTime = errors.New("ambiguous timestamp")
+
+// Zone abbreviations which name a single offset; any other, e.g. "CST", is ambiguous
+var unambiguousZones = map[string]bool{"UTC": true, "GMT": true, "Z": true}
+
+// How a column's timestamps are written
+type timeFormat struct {
+	layouts  []string
+	location *time.Location
+}
+
+// Configures a timestamp FieldParser for one column
+type TimeOption func(*timeFormat)
+
+// Parses timestamps with the given layouts in place of DefaultTimeLayouts
+func WithLayouts(layouts ...string) TimeOption {
+	return func(f *timeFormat) { f.layouts = layouts }
+}
+
+// Reads timestamps without an offset or zone in the given location, rather than UTC
+func WithLocation(loc *time.Location) TimeOption {
+	return func(f *timeFormat) { f.location = loc }
+}
+
+func newTimeFormat(opts []TimeOption) timeFormat {
+	f := timeFormat{layouts: DefaultTimeLayouts, location: time.UTC}
+	for _, opt := range opts {
+		opt(&f)
+	}
+	return f
+}
+
+// Parses a timestamp with every layout, honouring any offset or zone it carries.
+// A trailing IANA zone name, e.g. "Europe/Paris", or the abbreviation UTC or GMT
+// is also accepted; other abbreviations name several offsets and are rejected.
+// So are values which parse to different instants under different layouts, and
+// local times repeated or skipped by a daylight saving change.
+func (f timeFormat) parse(cell string) (time.Time, error) {
+	s := strings.TrimSpace(cell)
+
+	loc := f.location
+	if value, zone, ok := splitZone(s); ok {
+		zoneLoc, err := lookupZone(zone)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("timestamp %q: %w", cell, err)
+		}
+		s, loc = value, zoneLoc
+	}
+
+	var (
+		result   time.Time
+		matched  string
+		firstErr error
+	)
+	for _, layout := range f.layouts {
+		t, err := parseLayout(layout, s, loc)
+		if errors.Is(err, ErrAmbiguousTime) {
+			return time.Time{}, fmt.Errorf("timestamp %q: %w", cell, err)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if matched != "" && !t.Equal(result) {
+			return time.Time{}, fmt.Errorf("%w: %q is %s as %q but %s as %q", ErrAmbiguousTime, cell,
+				result.UTC().Format(TimestampLayout), matched, t.UTC().Format(TimestampLayout), layout)
+		}
+		result, matched = t, layout
+	}
+	if matched == "" {
+		return time.Time{}, fmt.Errorf("timestamp %q matches none of %d layouts: %w", cell, len(f.layouts), firstErr)
+	}
+	return result.UTC(), nil
+}
+
+// Parses a value with one layout; a value without an offset is a wall clock time in loc
+func parseLayout(layout, s string, loc *time.Location) (time.Time, error) {
+	if hasOffset(layout) {
+		return time.Parse(layout, s)
+	}
+	wall, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return resolveLocal(wall, loc)
+}
+
+// Reports whether a layout reads an offset or zone from the value
+func hasOffset(layout string) bool {
+	for _, element := range []string{"Z07", "-07", "MST"} {
+		if strings.Contains(layout, element) {
+			return true
+		}
+	}
+	return false
+}
+
+// Splits a trailing zone name from a timestamp, e.g. "Europe/Paris" or "UTC"
+func splitZone(s string) (string, string, bool) {
+	i := strings.LastIndexByte(s, ' ')
+	if i < 0 {
+		return "", "", false
+	}
+	zone := s[i+1:]
+	if zone == "" || !isZoneName(zone) {
+		return "", "", false
+	}
+	return strings.TrimSpace(s[:i]), zone, true
+}
+
+// Reports whether the text is a zone name or abbreviation rather than an offset
+func isZoneName(s string) bool {
+	for _, r := range s {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r == '/' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func lookupZone(zone string) (*time.Location, error) {
+	if unambiguousZones[strings.ToUpper(zone)] {
+		return time.UTC, nil
+	}
+	if !strings.Contains(zone, "/") {
+		return nil, fmt.Errorf("%w: zone abbreviation %q names several offsets; use an offset or IANA zone name", ErrAmbiguousTime, zone)
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown zone %q: %w", zone, err)
+	}
+	return loc, nil
+}
+
+// Returns the instant at which a wall clock time, parsed as UTC, occurs in loc.
+// Fails for a wall clock time which a daylight saving change in loc repeats, and
+// so occurs at two instants, or skips, and so never occurs.
+func resolveLocal(wall time.Time, loc *time.Location) (time.Time, error) {
+	if loc == time.UTC {
+		return wall, nil
+	}
+
+	// the offsets either side of any transition near the time
+	approx := wall.In(loc)
+	_, before := approx.Add(-24 * time.Hour).Zone()
+	_, after := approx.Add(24 * time.Hour).Zone()
+
+	var instants []time.Time
+	for _, offset := range []int{before, after} {
+		candidate := wall.Add(-time.Duration(offset) * time.Second)
+		if _, off := candidate.In(loc).Zone(); off == offset && !slices.ContainsFunc(instants, candidate.Equal) {
+			instants = append(instants, candidate)
+		}
+	}
+
+	switch len(instants) {
+	case 0:
+		return time.Time{}, fmt.Errorf("%w: %s does not occur in %s", ErrAmbiguousTime, wall.Format(time.DateTime), loc)
+	case 1:
+		return instants[0], nil
+	default:
+		return time.Time{}, fmt.Errorf("%w: %s occurs twice in %s", ErrAmbiguousTime, wall.Format(time.DateTime), loc)
+	}
+}
@@ -0,0 +1,81 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		cell          string
+		loc           *time.Location // nil for UTC
+		want          string         // the instant in RFC3339, or empty for an error
+		wantAmbiguous bool
+	}{
+		{name: "utc", cell: "2024-03-01 20:00:00", want: "2024-03-01T20:00:00Z"},
+		{name: "offset", cell: "2024-03-01T20:00:00+02:00", want: "2024-03-01T18:00:00Z"},
+		{name: "fractional seconds", cell: "2024-03-01 20:00:00.250", want: "2024-03-01T20:00:00.25Z"},
+		{name: "zone abbreviation UTC", cell: "2024-03-01 20:00:00 UTC", want: "2024-03-01T20:00:00Z"},
+		{name: "zone name", cell: "2024-03-01 20:00:00 Europe/Paris", want: "2024-03-01T19:00:00Z"},
+		{name: "zone name overrides location", cell: "2024-07-01 12:00:00 Europe/Paris", loc: newYork, want: "2024-07-01T10:00:00Z"},
+		{name: "ambiguous zone abbreviation", cell: "2024-03-01 20:00:00 CST", wantAmbiguous: true},
+		{name: "unknown zone name", cell: "2024-03-01 20:00:00 Mars/Olympus_Mons"},
+		{name: "location in winter", cell: "2024-01-15 12:00:00", loc: newYork, want: "2024-01-15T17:00:00Z"},
+		{name: "location in summer", cell: "2024-07-01 12:00:00", loc: newYork, want: "2024-07-01T16:00:00Z"},
+		{name: "before a gap", cell: "2024-03-10 01:59:59", loc: newYork, want: "2024-03-10T06:59:59Z"},
+		{name: "in a gap", cell: "2024-03-10 02:30:00", loc: newYork, wantAmbiguous: true},
+		{name: "after a gap", cell: "2024-03-10 03:00:00", loc: newYork, want: "2024-03-10T07:00:00Z"},
+		{name: "before an overlap", cell: "2024-11-03 00:59:59", loc: newYork, want: "2024-11-03T04:59:59Z"},
+		{name: "in an overlap", cell: "2024-11-03 01:30:00", loc: newYork, wantAmbiguous: true},
+		{name: "after an overlap", cell: "2024-11-03 02:00:00", loc: newYork, want: "2024-11-03T07:00:00Z"},
+		{name: "in a gap of a named zone", cell: "2024-03-31 02:30:00 Europe/Paris", wantAmbiguous: true},
+		{name: "offset in a gap", cell: "2024-03-10T02:30:00-05:00", loc: newYork, want: "2024-03-10T07:30:00Z"},
+		{name: "not a timestamp", cell: "yesterday"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []TimeOption
+			if tt.loc != nil {
+				opts = append(opts, WithLocation(tt.loc))
+			}
+			got, err := newTimeFormat(opts).parse(tt.cell)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("parsed %s, want an error", got.Format(time.RFC3339Nano))
+				}
+				if errors.Is(err, ErrAmbiguousTime) != tt.wantAmbiguous {
+					t.Errorf("error %v, want ambiguous %v", err, tt.wantAmbiguous)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Format(time.RFC3339Nano) != tt.want {
+				t.Errorf("parsed %s, want %s", got.Format(time.RFC3339Nano), tt.want)
+			}
+		})
+	}
+}
+
+// A value which two layouts read as different instants is rejected
+func TestParseTimestampLayouts(t *testing.T) {
+	format := newTimeFormat([]TimeOption{WithLayouts("01/02/2006 15:04", "02/01/2006 15:04")})
+	if _, err := format.parse("03/04/2024 20:00"); !errors.Is(err, ErrAmbiguousTime) {
+		t.Errorf("error %v, want ErrAmbiguousTime", err)
+	}
+	got, err := format.parse("03/03/2024 20:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 3, 20, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("parsed %s, want %s", got, want)
+	}
+}
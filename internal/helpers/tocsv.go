@@ -2,12 +2,179 @@ package helpers
 
 import (
 	"encoding/csv"
+	"fmt"
 	"os"
-
-	"github.com/hoodnoah/cod_data_request/internal/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func ToCSV[T types.CSVExportable](fileName string, header []string, items []T) error {
+// The struct tag adjusting a field's CSV output: "-" leaves the field out, and
+// "decimals=N" writes a float to N decimals rather than as few as represent it.
+const CSVTag = "csv"
+
+// Writes records of a struct type as CSV rows. The header and each cell's format
+// are derived from the fields' parquet tags, so CSV and parquet output agree:
+//
+//   - every field with a parquet name is a column of that name, in field order
+//   - a TIMESTAMP_MILLIS field is written in TimestampLayout
+//   - a time.Duration field is written as two columns, in seconds and in ISO-8601,
+//     named after the parquet column without its _ns suffix, e.g. time_played_s
+//     and time_played_iso for time_played_ns
+//   - a nil pointer is written as an empty cell
+type CSVEncoder struct {
+	typ    reflect.Type
+	header []string
+	fields []csvField
+}
+
+// Appends the cells of one field to a row
+type csvField struct {
+	index  int
+	column int // the header position of the field's first cell
+	format func(row []string, v reflect.Value) []string
+}
+
+// Creates an encoder for the struct type t, failing if a column's field has a
+// type CSV cannot be written from.
+func NewCSVEncoder(t reflect.Type) (*CSVEncoder, error) {
+	e := &CSVEncoder{typ: t}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name := parquetName(field.Tag.Get("parquet"))
+		options := field.Tag.Get(CSVTag)
+		if name == "" || options == "-" {
+			continue
+		}
+
+		format, err := cellFormat(field, options)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		e.fields = append(e.fields, csvField{index: i, column: len(e.header), format: format})
+
+		elem := field.Type
+		if elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if elem == reflect.TypeFor[time.Duration]() {
+			base := strings.TrimSuffix(name, "_ns")
+			e.header = append(e.header, base+"_s", base+"_iso")
+		} else {
+			e.header = append(e.header, name)
+		}
+	}
+	return e, nil
+}
+
+// Returns the CSV header: the name of each column, in field order
+func (e *CSVEncoder) Header() []string {
+	return e.header
+}
+
+// Returns the name of the column the struct's field is written to, or false if it
+// is not written
+func (e *CSVEncoder) column(field int) (string, bool) {
+	for _, f := range e.fields {
+		if f.index == field {
+			return e.header[f.column], true
+		}
+	}
+	return "", false
+}
+
+// Formats a record's cells. The record is a struct, a pointer to one, or a
+// StructRecord wrapping one.
+func (e *CSVEncoder) Row(record any) []string {
+	if r, ok := record.(StructRecord); ok {
+		record = r.Struct()
+	}
+	v := reflect.Indirect(reflect.ValueOf(record))
+
+	row := make([]string, 0, len(e.header))
+	for _, f := range e.fields {
+		row = f.format(row, v.Field(f.index))
+	}
+	return row
+}
+
+// Returns the formatter of a field's cells, by its type and tags
+func cellFormat(field reflect.StructField, options string) (func([]string, reflect.Value) []string, error) {
+	decimals := -1
+	for _, option := range strings.Split(options, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch key {
+		case "":
+		case "decimals":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid %s tag decimals %q", CSVTag, val)
+			}
+			decimals = n
+		default:
+			return nil, fmt.Errorf("unknown %s tag option %q", CSVTag, key)
+		}
+	}
+
+	elem, ptr := field.Type, field.Type.Kind() == reflect.Pointer
+	if ptr {
+		elem = elem.Elem()
+	}
+
+	var format func([]string, reflect.Value) []string
+	switch {
+	case elem == reflect.TypeFor[time.Duration]():
+		format = func(row []string, v reflect.Value) []string {
+			d := time.Duration(v.Int())
+			return append(row, FormatDurationSeconds(&d), FormatDurationISO(&d))
+		}
+	case elem.Kind() == reflect.Int64 && parquetTagValue(field.Tag.Get("parquet"), "convertedtype") == "TIMESTAMP_MILLIS":
+		format = func(row []string, v reflect.Value) []string {
+			return append(row, FormatUnixMillis(v.Int()))
+		}
+	case elem.Kind() >= reflect.Int && elem.Kind() <= reflect.Int64:
+		format = func(row []string, v reflect.Value) []string {
+			return append(row, strconv.FormatInt(v.Int(), 10))
+		}
+	case elem.Kind() == reflect.Float32 || elem.Kind() == reflect.Float64:
+		format = func(row []string, v reflect.Value) []string {
+			return append(row, strconv.FormatFloat(v.Float(), 'f', decimals, 64))
+		}
+	case elem.Kind() == reflect.String:
+		format = func(row []string, v reflect.Value) []string {
+			return append(row, v.String())
+		}
+	case elem.Kind() == reflect.Bool:
+		format = func(row []string, v reflect.Value) []string {
+			return append(row, strconv.FormatBool(v.Bool()))
+		}
+	default:
+		return nil, fmt.Errorf("cannot write %s to CSV", field.Type)
+	}
+	if !ptr {
+		return format, nil
+	}
+
+	// a nil pointer is an empty cell in each of the field's columns
+	empty := 1
+	if elem == reflect.TypeFor[time.Duration]() {
+		empty = 2
+	}
+	return func(row []string, v reflect.Value) []string {
+		if v.IsNil() {
+			for range empty {
+				row = append(row, "")
+			}
+			return row
+		}
+		return format(row, v.Elem())
+	}, nil
+}
+
+// Writes the items to a new CSV at fileName, with the encoder's header
+func ToCSV[T any](fileName string, encoder *CSVEncoder, items []T) error {
 	file, err := os.Create(fileName)
 	if err != nil {
 		return err
@@ -17,12 +184,12 @@ func ToCSV[T types.CSVExportable](fileName string, header []string, items []T) e
 	writer := csv.NewWriter(file)
 	defer writer.Flush()
 
-	if err := writer.Write(header); err != nil {
+	if err := writer.Write(encoder.Header()); err != nil {
 		return err
 	}
 
 	for _, item := range items {
-		if err := writer.Write(item.ToStringSlice()); err != nil {
+		if err := writer.Write(encoder.Row(item)); err != nil {
 			return err
 		}
 	}
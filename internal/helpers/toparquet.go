@@ -26,6 +26,7 @@ func ToParquet[T types.ParquetExportable](outputDir string, items []T, schema an
 	}
 	pw.RowGroupSize = 128 * 1024 * 1024 // 128MB
 	pw.CompressionType = parquet.CompressionCodec_UNCOMPRESSED
+	pw.Footer.KeyValueMetadata = durationMetadata(schema)
 
 	// Write each record
 	for _, item := range items {
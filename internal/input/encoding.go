@@ -0,0 +1,227 @@
+package input
+
+import (
+	// std
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	// external
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// The size of the prefix examined for a byte order mark or meta charset. A part
+// whose prefix is valid UTF-8 is still checked as it is read; see utf8Fallback.
+const sniffSize = 1024
+
+// How a part's bytes were detected to be encoded, and so how they are decoded to UTF-8
+type Encoding struct {
+	// the encoding's canonical name, e.g. "utf-8" or "windows-1252"
+	Name string
+
+	// how it was detected: "bom", "meta" or "sniffed"
+	Source string
+
+	enc    encoding.Encoding
+	bomLen int
+
+	// set for a part sniffed as UTF-8, which may turn out not to be once read
+	fallback *fallbackState
+}
+
+// Describes the encoding actually used to read the part so far, e.g.
+// "windows-1252 (sniffed, after 52341 bytes of utf-8)" for a part sniffed as
+// UTF-8 whose later bytes were not
+func (e Encoding) String() string {
+	if e.fallback != nil && e.fallback.switched {
+		return fmt.Sprintf("windows-1252 (%s, after %d bytes of utf-8)", e.Source, e.fallback.utf8Bytes)
+	}
+	return e.Name + " (" + e.Source + ")"
+}
+
+var boms = []struct {
+	bom  []byte
+	name string
+}{
+	{[]byte{0xef, 0xbb, 0xbf}, "utf-8"},
+	{[]byte{0xfe, 0xff}, "utf-16be"},
+	{[]byte{0xff, 0xfe}, "utf-16le"},
+}
+
+// Detects the encoding of a document from its first bytes: a byte order mark takes
+// precedence, then a meta charset declaration. Undeclared documents are read as
+// UTF-8 if the sample is valid UTF-8, switching to Windows-1252 should a later
+// byte not be, and as Windows-1252 otherwise.
+func detectEncoding(sample []byte) Encoding {
+	if len(sample) > sniffSize {
+		sample = sample[:sniffSize]
+	}
+
+	for _, b := range boms {
+		if bytes.HasPrefix(sample, b.bom) {
+			enc, name := charset.Lookup(b.name)
+			return Encoding{Name: name, Source: "bom", enc: enc, bomLen: len(b.bom)}
+		}
+	}
+
+	if label := metaCharset(sample); label != "" {
+		// a document which can declare its charset in ASCII is not UTF-16
+		if strings.HasPrefix(strings.ToLower(label), "utf-16") {
+			label = "utf-8"
+		}
+		if enc, name := charset.Lookup(label); enc != nil {
+			return Encoding{Name: name, Source: "meta", enc: enc}
+		}
+	}
+
+	if validUTF8Prefix(sample) {
+		return Encoding{Name: "utf-8", Source: "sniffed", enc: encoding.Nop, fallback: &fallbackState{}}
+	}
+	enc, name := charset.Lookup("windows-1252")
+	return Encoding{Name: name, Source: "sniffed", enc: enc}
+}
+
+// Wraps a reader positioned at the start of the part so that it yields UTF-8
+func (e Encoding) decode(r io.Reader) (io.Reader, error) {
+	if e.bomLen > 0 {
+		if _, err := io.CopyN(io.Discard, r, int64(e.bomLen)); err != nil {
+			return nil, err
+		}
+	}
+	if e.fallback != nil {
+		return transform.NewReader(r, &utf8Fallback{state: e.fallback}), nil
+	}
+	if e.enc == nil || e.enc == encoding.Nop {
+		return r, nil
+	}
+	return transform.NewReader(r, e.enc.NewDecoder()), nil
+}
+
+// Whether a part sniffed as UTF-8 has been switched to Windows-1252, and after how
+// many bytes. Shared by every read of the part, each of which sets it on switching.
+type fallbackState struct {
+	switched  bool
+	utf8Bytes int64
+}
+
+// Passes a part sniffed as UTF-8 through unchanged up to its first byte which is
+// not valid UTF-8, then decodes the rest as Windows-1252. The sniffed prefix is
+// only a kilobyte, and an export's first non-ASCII text, e.g. an accented map or
+// player name, may come much later.
+type utf8Fallback struct {
+	state     *fallbackState
+	utf8Bytes int64                 // passed through by this read
+	decoder   transform.Transformer // once switched
+}
+
+func (f *utf8Fallback) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if f.decoder != nil {
+		return f.decoder.Transform(dst, src, atEOF)
+	}
+
+	for nSrc < len(src) {
+		size := 1
+		if c := src[nSrc]; c >= utf8.RuneSelf {
+			var r rune
+			r, size = utf8.DecodeRune(src[nSrc:])
+			if r == utf8.RuneError && size == 1 {
+				if !atEOF && !utf8.FullRune(src[nSrc:]) {
+					err = transform.ErrShortSrc
+					break
+				}
+				f.utf8Bytes += int64(nSrc)
+				f.switchTo1252()
+				n, m, err := f.decoder.Transform(dst[nDst:], src[nSrc:], atEOF)
+				return nDst + n, nSrc + m, err
+			}
+		}
+		if nDst+size > len(dst) {
+			err = transform.ErrShortDst
+			break
+		}
+		nDst += copy(dst[nDst:], src[nSrc:nSrc+size])
+		nSrc += size
+	}
+	f.utf8Bytes += int64(nSrc)
+	return nDst, nSrc, err
+}
+
+func (f *utf8Fallback) switchTo1252() {
+	enc, _ := charset.Lookup("windows-1252")
+	f.decoder = enc.NewDecoder()
+	f.state.switched = true
+	f.state.utf8Bytes = f.utf8Bytes
+}
+
+func (f *utf8Fallback) Reset() {
+	f.utf8Bytes = 0
+	f.decoder = nil
+}
+
+// Returns the charset declared by a meta element before the body, if any
+func metaCharset(sample []byte) string {
+	z := html.NewTokenizer(bytes.NewReader(sample))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "body":
+				return ""
+			case "meta":
+				if label := metaTagCharset(z, hasAttr); label != "" {
+					return label
+				}
+			}
+		}
+	}
+}
+
+// Reads the charset from a meta tag's charset attribute, or from the content of
+// an http-equiv="Content-Type" declaration
+func metaTagCharset(z *html.Tokenizer, hasAttr bool) string {
+	var (
+		content   string
+		isContent bool
+	)
+	for hasAttr {
+		var key, val []byte
+		key, val, hasAttr = z.TagAttr()
+		switch strings.ToLower(string(key)) {
+		case "charset":
+			return strings.TrimSpace(string(val))
+		case "http-equiv":
+			isContent = strings.EqualFold(string(val), "content-type")
+		case "content":
+			content = string(val)
+		}
+	}
+
+	if isContent {
+		if _, params, err := mime.ParseMediaType(content); err == nil {
+			return params["charset"]
+		}
+	}
+	return ""
+}
+
+// Reports whether the sample is valid UTF-8, ignoring a rune cut off at its end
+func validUTF8Prefix(sample []byte) bool {
+	for i := len(sample) - 1; i >= 0 && i > len(sample)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(sample[i]) {
+			if !utf8.FullRune(sample[i:]) {
+				sample = sample[:i]
+			}
+			break
+		}
+	}
+	return utf8.Valid(sample)
+}
@@ -0,0 +1,153 @@
+package input
+
+import (
+	// std
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// An undeclared export whose first non-ASCII text comes after the sniffed prefix
+func lateExport(text string) []byte {
+	return []byte("<html><body><h1>Game</h1>" + strings.Repeat("<p>ascii</p>", 400) + "<p>" + text + "</p></body></html>")
+}
+
+func TestLateEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		want     string
+		encoding string
+	}{
+		{
+			name:     "windows-1252",
+			data:     lateExport("Caf\xe9 \x93Rust\x94"),
+			want:     "Café “Rust”",
+			encoding: fmt.Sprintf("windows-1252 (sniffed, after %d bytes of utf-8)", bytes.IndexByte(lateExport("Caf\xe9"), 0xe9)),
+		},
+		{
+			name:     "utf-8",
+			data:     lateExport("Café “Rust” 🎮"),
+			want:     "Café “Rust” 🎮",
+			encoding: "utf-8 (sniffed)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.data) <= 2*sniffSize {
+				t.Fatal("the export's non-ASCII text is within the sniffed prefix")
+			}
+
+			t.Run("stream", func(t *testing.T) {
+				in, err := FromReader("export", iotest.OneByteReader(bytes.NewReader(tt.data)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := in.Report.Encodings; len(got) != 1 || got[0] != "utf-8 (sniffed)" {
+					t.Errorf("encodings before reading %q, want utf-8 (sniffed)", got)
+				}
+				r := in.Reader()
+				defer r.Close()
+				text, err := io.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(string(text), "<p>"+tt.want+"</p>") {
+					t.Errorf("read %q, want %q", text[len(text)-40:], tt.want)
+				}
+				if got := in.Report.Encodings; len(got) != 1 || got[0] != tt.encoding {
+					t.Errorf("encodings %q, want %q", got, tt.encoding)
+				}
+			})
+
+			t.Run("document", func(t *testing.T) {
+				fileName := filepath.Join(t.TempDir(), "export.html")
+				if err := os.WriteFile(fileName, tt.data, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				in, err := Open(fileName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer in.Close()
+				doc, err := in.Document()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := doc.Find("p").Last().Text(); got != tt.want {
+					t.Errorf("read %q, want %q", got, tt.want)
+				}
+				if got := in.Report.Encodings; len(got) != 1 || got[0] != tt.encoding {
+					t.Errorf("encodings %q, want %q", got, tt.encoding)
+				}
+			})
+		})
+	}
+}
+
+// Reading a part again, as a document or a stream, reports the same switch
+func TestLateEncodingReadTwice(t *testing.T) {
+	data := lateExport("Caf\xe9")
+	want := fmt.Sprintf("windows-1252 (sniffed, after %d bytes of utf-8)", bytes.IndexByte(data, 0xe9))
+
+	fileName := filepath.Join(t.TempDir(), "export.html")
+	if err := os.WriteFile(fileName, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	in, err := Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+
+	reads := []struct {
+		name string
+		read func() error
+	}{
+		{name: "document", read: func() error {
+			_, err := in.Document()
+			return err
+		}},
+		{name: "stream", read: func() error {
+			r := in.Reader()
+			defer r.Close()
+			_, err := io.ReadAll(r)
+			return err
+		}},
+		{name: "document again", read: func() error {
+			_, err := in.Document()
+			return err
+		}},
+	}
+	for _, read := range reads {
+		if err := read.read(); err != nil {
+			t.Fatalf("%s: %v", read.name, err)
+		}
+		if got := in.Report.Encodings; len(got) != 1 || got[0] != want {
+			t.Errorf("%s: encodings %q, want %q", read.name, got, want)
+		}
+	}
+}
+
+// A declared or detected Windows-1252 part is decoded from its first byte
+func TestSniffedWindows1252(t *testing.T) {
+	in, err := FromReader("export", bytes.NewReader([]byte("<p>Caf\xe9</p>")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := in.Report.Encodings; len(got) != 1 || got[0] != "windows-1252 (sniffed)" {
+		t.Errorf("encodings %q, want windows-1252 (sniffed)", got)
+	}
+	text, err := io.ReadAll(in.Reader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(text) != "<p>Café</p>" {
+		t.Errorf("read %q, want <p>Café</p>", text)
+	}
+}
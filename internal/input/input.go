@@ -0,0 +1,477 @@
+package input
+
+import (
+	// std
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	// external
+	"github.com/PuerkitoBio/goquery"
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/net/html"
+)
+
+// The path which reads the input from stdin
+const Stdin = "-"
+
+var (
+	zipMagic  = []byte("PK\x03\x04")
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Part is a single HTML document within an input, e.g. one file of a
+// multi-part export inside an archive.
+type Part struct {
+	Name string
+
+	// the encoding detected from the part's first bytes, decoded to UTF-8 by Open;
+	// its String describes any switch made while reading
+	Encoding Encoding
+
+	open func() (io.ReadCloser, error)
+}
+
+// Opens the part for reading as UTF-8; the caller must close the returned reader.
+func (p Part) Open() (io.ReadCloser, error) {
+	r, err := p.open()
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := p.Encoding.decode(r)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{decoded, r}, nil
+}
+
+// Detects the part's encoding from its first bytes
+func (p *Part) sniff() error {
+	r, err := p.open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	sample := make([]byte, sniffSize)
+	n, err := io.ReadFull(r, sample)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+	p.Encoding = detectEncoding(sample[:n])
+	return nil
+}
+
+// An archive entry which was not used as part of the export, and why.
+type SkippedEntry struct {
+	Name   string
+	Reason string
+}
+
+// Describes how an input was resolved into HTML parts.
+type Report struct {
+	Source  string
+	Archive bool
+	Used    []string
+	Skipped []SkippedEntry
+
+	// the compression the input was decompressed from, e.g. "gzip", if any
+	Compression string
+
+	// the distinct encodings of the parts, e.g. "windows-1252 (meta)", in part order;
+	// updated once the parts are read, as a part sniffed as UTF-8 may not be
+	Encodings []string
+}
+
+// An opened input: the ordered HTML parts making up one logical export.
+type Input struct {
+	Parts  []Part
+	Report Report
+	closer io.Closer
+}
+
+// Opens the input at the provided path, which may either be a single HTML
+// file or the .zip archive delivered by Activision, or Stdin. Gzip and zstd
+// compressed inputs are detected by their signature and decompressed.
+func Open(filePath string) (*Input, error) {
+	if filePath == Stdin {
+		return openStream("stdin", os.Stdin)
+	}
+
+	magic, err := readMagic(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(path.Ext(filePath), ".zip") || bytes.HasPrefix(magic, zipMagic) {
+		return openZip(filePath)
+	}
+
+	if compressionOf(magic) != "" {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return openStream(filePath, f)
+	}
+
+	in := &Input{
+		Parts: []Part{{
+			Name: path.Base(filePath),
+			open: func() (io.ReadCloser, error) { return os.Open(filePath) },
+		}},
+		Report: Report{Source: filePath, Used: []string{path.Base(filePath)}},
+	}
+	if err := in.sniffParts(); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// Opens an input read from r, which the caller remains responsible for closing.
+// As for stdin, compressed data and zip archives are detected, and r can only be
+// read once. source names the input in reports.
+func FromReader(source string, r io.Reader) (*Input, error) {
+	return openStream(source, io.NopCloser(r))
+}
+
+// Opens an input which can only be read once, such as stdin or a compressed file,
+// decompressing it if needed. A zip archive arriving this way is buffered in
+// memory, as reading one requires random access.
+func openStream(source string, r io.ReadCloser) (*Input, error) {
+	in := &Input{Report: Report{Source: source}, closer: r}
+
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(zstdMagic))
+	if format := compressionOf(magic); format != "" {
+		dr, err := decompress(br, format)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("failed to decompress %s: %w", source, err)
+		}
+		in.Report.Compression = format
+		in.closer = closers{dr, r}
+
+		br = bufio.NewReader(dr)
+		magic, _ = br.Peek(len(zipMagic))
+	}
+
+	if bytes.HasPrefix(magic, zipMagic) {
+		data, err := io.ReadAll(br)
+		in.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive %s: %w", source, err)
+		}
+		zin := newZipInput(source, zr, nil)
+		zin.Report.Compression = in.Report.Compression
+		if err := zin.sniffParts(); err != nil {
+			return nil, err
+		}
+		return zin, nil
+	}
+
+	// the part can only be read once, so sniff it without consuming anything
+	sample, _ := br.Peek(sniffSize)
+
+	name := strings.TrimSuffix(strings.TrimSuffix(path.Base(source), ".gz"), ".zst")
+	read := false
+	in.Parts = []Part{{
+		Name:     name,
+		Encoding: detectEncoding(sample),
+		open: func() (io.ReadCloser, error) {
+			if read {
+				return nil, fmt.Errorf("%s can only be read once", source)
+			}
+			read = true
+			return io.NopCloser(br), nil
+		},
+	}}
+	in.Report.Used = []string{name}
+	in.reportEncodings()
+	return in, nil
+}
+
+// Detects the encoding of every part, recording the distinct encodings in the report
+func (in *Input) sniffParts() error {
+	for i := range in.Parts {
+		if err := in.Parts[i].sniff(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", in.Parts[i].Name, err)
+		}
+	}
+	in.reportEncodings()
+	return nil
+}
+
+// Records the distinct encodings the parts are read in, as far as they have been read
+func (in *Input) reportEncodings() {
+	in.Report.Encodings = nil
+	for _, part := range in.Parts {
+		if enc := part.Encoding.String(); !slices.Contains(in.Report.Encodings, enc) {
+			in.Report.Encodings = append(in.Report.Encodings, enc)
+		}
+	}
+}
+
+// Releases any resources held by the input.
+func (in *Input) Close() error {
+	if in.closer == nil {
+		return nil
+	}
+	return in.closer.Close()
+}
+
+// Parses every part of the input, returning them as a single document.
+// The body contents of each subsequent part are appended to the first, in order,
+// so that sections split across parts remain contiguous.
+func (in *Input) Document() (*goquery.Document, error) {
+	if len(in.Parts) == 0 {
+		return nil, fmt.Errorf("no HTML documents found in %s", in.Report.Source)
+	}
+
+	var doc *goquery.Document
+	for _, part := range in.Parts {
+		partDoc, err := parsePart(part)
+		if err != nil {
+			return nil, err
+		}
+
+		if doc == nil {
+			doc = partDoc
+			continue
+		}
+		doc.Find("body").First().AppendSelection(partDoc.Find("body").First().Contents())
+	}
+
+	in.reportEncodings()
+	return doc, nil
+}
+
+// Returns a reader over every part of the input in sequence, for streaming parsers.
+// Each part is opened only once the previous one has been read to completion.
+func (in *Input) Reader() io.ReadCloser {
+	return &partsReader{in: in, parts: in.Parts}
+}
+
+type partsReader struct {
+	in      *Input
+	parts   []Part
+	current io.ReadCloser
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.parts) == 0 {
+				return 0, io.EOF
+			}
+			next, err := r.parts[0].Open()
+			if err != nil {
+				return 0, fmt.Errorf("failed to open %s: %w", r.parts[0].Name, err)
+			}
+			r.current, r.parts = next, r.parts[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			r.in.reportEncodings()
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *partsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
+
+func parsePart(part Part) (*goquery.Document, error) {
+	r, err := part.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", part.Name, err)
+	}
+	defer r.Close()
+
+	root, err := html.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML in %s: %w", part.Name, err)
+	}
+	return goquery.NewDocumentFromNode(root), nil
+}
+
+// Reads the first bytes of the file, which identify zip archives and compression
+func readMagic(filePath string) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(zstdMagic))
+	n, err := io.ReadFull(f, magic)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return magic[:n], nil
+}
+
+// Returns the compression format signalled by the leading bytes, if any
+func compressionOf(magic []byte) string {
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return "gzip"
+	case bytes.HasPrefix(magic, zstdMagic):
+		return "zstd"
+	default:
+		return ""
+	}
+}
+
+func decompress(r io.Reader, format string) (io.ReadCloser, error) {
+	switch format {
+	case "gzip":
+		return gzip.NewReader(r)
+	case "zstd":
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", format)
+	}
+}
+
+// Closes each in turn, returning the first error
+type closers []io.Closer
+
+func (c closers) Close() error {
+	var first error
+	for _, closer := range c {
+		if err := closer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func openZip(filePath string) (*Input, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", filePath, err)
+	}
+
+	in := newZipInput(filePath, &zr.Reader, zr)
+	if err := in.sniffParts(); err != nil {
+		in.Close()
+		return nil, err
+	}
+	return in, nil
+}
+
+func newZipInput(source string, zr *zip.Reader, closer io.Closer) *Input {
+	in := &Input{
+		Report: Report{Source: source, Archive: true},
+		closer: closer,
+	}
+
+	var htmlFiles []*zip.File
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if reason := skipReason(f.Name); reason != "" {
+			in.Report.Skipped = append(in.Report.Skipped, SkippedEntry{Name: f.Name, Reason: reason})
+			continue
+		}
+		htmlFiles = append(htmlFiles, f)
+	}
+
+	// exports split across several files are numbered; keep them in sequence
+	sort.SliceStable(htmlFiles, func(i, j int) bool {
+		return naturalLess(htmlFiles[i].Name, htmlFiles[j].Name)
+	})
+
+	for _, f := range htmlFiles {
+		in.Parts = append(in.Parts, Part{Name: f.Name, open: f.Open})
+		in.Report.Used = append(in.Report.Used, f.Name)
+	}
+
+	return in
+}
+
+// Returns why an archive entry should not be read as part of the export,
+// or the empty string if it should be.
+func skipReason(name string) string {
+	if strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), "._") {
+		return "macOS metadata"
+	}
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".html", ".htm":
+		return ""
+	default:
+		return "not an HTML file"
+	}
+}
+
+// Compares strings such that embedded runs of digits are ordered numerically,
+// e.g. "part_2.html" sorts before "part_10.html".
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		aDigits, bDigits := leadingDigits(a), leadingDigits(b)
+		if aDigits != "" && bDigits != "" {
+			aNum, bNum := strings.TrimLeft(aDigits, "0"), strings.TrimLeft(bDigits, "0")
+			if len(aNum) != len(bNum) {
+				return len(aNum) < len(bNum)
+			}
+			if aNum != bNum {
+				return aNum < bNum
+			}
+			a, b = a[len(aDigits):], b[len(bDigits):]
+			continue
+		}
+
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
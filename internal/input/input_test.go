@@ -0,0 +1,132 @@
+package input
+
+import (
+	// std
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	// external
+	"github.com/PuerkitoBio/goquery"
+)
+
+// The data cells of the export fixtures' two parts, in the order they are read
+var fixtureCells = []string{"Rust", "Shipment"}
+
+// Reads the data cells of every part of the input, both as one document and as a
+// stream, failing the test unless they agree
+func readCells(t *testing.T, open func() *Input) []string {
+	t.Helper()
+	in := open()
+	doc, err := in.Document()
+	in.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells := doc.Find("td").Map(func(_ int, s *goquery.Selection) string { return s.Text() })
+
+	in = open()
+	defer in.Close()
+	r := in.Reader()
+	defer r.Close()
+	text, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streamed []string
+	for _, part := range strings.Split(string(text), "<td>")[1:] {
+		streamed = append(streamed, part[:strings.Index(part, "</td>")])
+	}
+	if !reflect.DeepEqual(cells, streamed) {
+		t.Fatalf("document cells %q, stream cells %q", cells, streamed)
+	}
+	return cells
+}
+
+// An archive's HTML parts are read in numeric order; other entries are skipped
+func TestOpenZip(t *testing.T) {
+	open := func() *Input {
+		in, err := Open(filepath.Join("testdata", "export.zip"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return in
+	}
+
+	if got := readCells(t, open); !reflect.DeepEqual(got, fixtureCells) {
+		t.Errorf("cells %q, want %q", got, fixtureCells)
+	}
+
+	in := open()
+	defer in.Close()
+	report := in.Report
+	if !report.Archive || report.Compression != "" {
+		t.Errorf("report %+v, want an uncompressed archive", report)
+	}
+	if want := []string{"export/part_2.html", "export/part_10.html"}; !reflect.DeepEqual(report.Used, want) {
+		t.Errorf("used %q, want %q", report.Used, want)
+	}
+	wantSkipped := []SkippedEntry{
+		{Name: "export/readme.txt", Reason: "not an HTML file"},
+		{Name: "__MACOSX/export/._part_2.html", Reason: "macOS metadata"},
+	}
+	if !reflect.DeepEqual(report.Skipped, wantSkipped) {
+		t.Errorf("skipped %+v, want %+v", report.Skipped, wantSkipped)
+	}
+}
+
+// Compressed fixtures are decompressed by their signature, whether opened by path
+// or read from a stream such as stdin
+func TestOpenCompressed(t *testing.T) {
+	tests := []struct {
+		file        string
+		compression string
+		archive     bool
+		used        []string
+	}{
+		{file: "export.html.gz", compression: "gzip", used: []string{"export.html"}},
+		{file: "export.html.zst", compression: "zstd", used: []string{"export.html"}},
+		{file: "export.zip.gz", compression: "gzip", archive: true, used: []string{"export/part_2.html", "export/part_10.html"}},
+	}
+	for _, tt := range tests {
+		fileName := filepath.Join("testdata", tt.file)
+		opens := map[string]func(t *testing.T) *Input{
+			"path": func(t *testing.T) *Input {
+				in, err := Open(fileName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return in
+			},
+			"stream": func(t *testing.T) *Input {
+				f, err := os.Open(fileName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { f.Close() })
+				in, err := FromReader(tt.file, f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return in
+			},
+		}
+		for name, open := range opens {
+			t.Run(tt.file+"/"+name, func(t *testing.T) {
+				if got := readCells(t, func() *Input { return open(t) }); !reflect.DeepEqual(got, fixtureCells) {
+					t.Errorf("cells %q, want %q", got, fixtureCells)
+				}
+
+				in := open(t)
+				defer in.Close()
+				report := in.Report
+				if report.Compression != tt.compression || report.Archive != tt.archive || !reflect.DeepEqual(report.Used, tt.used) {
+					t.Errorf("report %+v, want %s compressed, archive %v, using %q", report, tt.compression, tt.archive, tt.used)
+				}
+			})
+		}
+	}
+}
@@ -1,9 +1,5 @@
 package types
 
-type CSVExportable interface {
-	ToStringSlice() []string
-}
-
 type ParquetExportable interface {
 }
 